@@ -0,0 +1,87 @@
+//go:build use_easyjson
+// +build use_easyjson
+
+package benchmark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+var (
+	shortString = "The quick brown fox"
+	longString  = strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 32)
+	htmlString  = strings.Repeat(`<a href="/path?x=1&y=2">link</a> `, 32)
+
+	stringsText = []byte(`["` + shortString + `", "` + longString + `", "` + strings.ReplaceAll(htmlString, `"`, `\"`) + `"]`)
+)
+
+func benchmarkWriterString(b *testing.B, s string) {
+	b.SetBytes(int64(len(s)))
+	w := jwriter.Writer{}
+	for i := 0; i < b.N; i++ {
+		w.String(s)
+		w.Buffer.Buf = w.Buffer.Buf[:0]
+	}
+}
+
+func BenchmarkEJ_WriterString_Short(b *testing.B) {
+	benchmarkWriterString(b, shortString)
+}
+
+func BenchmarkEJ_WriterString_Long(b *testing.B) {
+	benchmarkWriterString(b, longString)
+}
+
+func BenchmarkEJ_WriterString_HTML(b *testing.B) {
+	benchmarkWriterString(b, htmlString)
+}
+
+func BenchmarkEJ_LexerSkipStrings(b *testing.B) {
+	b.SetBytes(int64(len(stringsText)))
+	for i := 0; i < b.N; i++ {
+		l := jlexer.Lexer{Data: stringsText}
+		l.SkipRecursive()
+		if err := l.Error(); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkEJ_LexerStrictStrings(b *testing.B) {
+	b.SetBytes(int64(len(stringsText)))
+	for i := 0; i < b.N; i++ {
+		l := jlexer.Lexer{Data: stringsText, Strict: true}
+		l.Interface()
+		if err := l.Error(); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func benchmarkLexerString(b *testing.B, s string) {
+	data := []byte(`"` + s + `"`)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		l := jlexer.Lexer{Data: data}
+		l.UnsafeString()
+		if err := l.Error(); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkEJ_LexerString_Short(b *testing.B) {
+	benchmarkLexerString(b, shortString)
+}
+
+func BenchmarkEJ_LexerString_Long(b *testing.B) {
+	benchmarkLexerString(b, longString)
+}
+
+func BenchmarkEJ_LexerString_Escaped(b *testing.B) {
+	benchmarkLexerString(b, strings.ReplaceAll(htmlString, `"`, `\"`))
+}
@@ -41,6 +41,9 @@ type Generator struct {
 	LeaveTemps  bool
 	NoFormat    bool
 	SimpleBytes bool
+
+	BytesEncoding string
+	SortMapKeys   bool
 }
 
 // writeStub outputs an initial stub for marshalers/unmarshalers so that the package
@@ -134,6 +137,12 @@ func (g *Generator) writeMain() (path string, err error) {
 	if g.SimpleBytes {
 		fmt.Fprintln(f, "  g.SimpleBytes()")
 	}
+	if g.BytesEncoding != "" {
+		fmt.Fprintf(f, "  g.SetBytesEncoding(%q)\n", g.BytesEncoding)
+	}
+	if g.SortMapKeys {
+		fmt.Fprintln(f, "  g.SortMapKeys()")
+	}
 	if g.SkipMemberNameUnescaping {
 		fmt.Fprintln(f, "  g.SkipMemberNameUnescaping()")
 	}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 )
 
 // PoolConfig contains configuration for the allocation and reuse strategy.
@@ -15,46 +16,72 @@ type PoolConfig struct {
 	MaxSize    int // Maximum chunk size that will be allocated.
 }
 
-var config = PoolConfig{
+// Pool allocates and reuses buffer chunks according to its own PoolConfig. A Pool is safe for
+// concurrent use.
+type Pool struct {
+	config PoolConfig
+
+	// Reuse pool: chunk size -> pool.
+	buffers map[int]*sync.Pool
+}
+
+// defaultConfig is the strategy of the default pool, its fields also replace the fields of other
+// configurations that are not positive.
+var defaultConfig = PoolConfig{
 	StartSize:  128,
 	PooledSize: 512,
 	MaxSize:    32768,
 }
 
-// Reuse pool: chunk size -> pool.
-var buffers = map[int]*sync.Pool{}
+// NewPool creates a pool with the given allocation and reuse strategy. Fields that are not
+// positive are set to their default values: 128, 512 and 32768 respectively.
+func NewPool(cfg PoolConfig) *Pool {
+	if cfg.StartSize <= 0 {
+		cfg.StartSize = defaultConfig.StartSize
+	}
+	if cfg.PooledSize <= 0 {
+		cfg.PooledSize = defaultConfig.PooledSize
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultConfig.MaxSize
+	}
 
-func initBuffers() {
-	for l := config.PooledSize; l <= config.MaxSize; l *= 2 {
-		buffers[l] = new(sync.Pool)
+	p := &Pool{config: cfg, buffers: map[int]*sync.Pool{}}
+	for l := cfg.PooledSize; l <= cfg.MaxSize; l *= 2 {
+		p.buffers[l] = new(sync.Pool)
 	}
+	return p
 }
 
+// defaultPool is used by the buffers that do not have a Pool set.
+var defaultPool atomic.Pointer[Pool]
+
 func init() {
-	initBuffers()
+	defaultPool.Store(NewPool(defaultConfig))
 }
 
-// Init sets up a non-default pooling and allocation strategy. Should be run before serialization is done.
+// Init sets up a non-default pooling and allocation strategy for the buffers that do not have a
+// Pool set. Should be run before serialization is done; the buffers already in use may keep
+// using the previous strategy.
 func Init(cfg PoolConfig) {
-	config = cfg
-	initBuffers()
+	defaultPool.Store(NewPool(cfg))
 }
 
 // putBuf puts a chunk to reuse pool if it can be reused.
-func putBuf(buf []byte) {
+func (p *Pool) putBuf(buf []byte) {
 	size := cap(buf)
-	if size < config.PooledSize {
+	if size < p.config.PooledSize {
 		return
 	}
-	if c := buffers[size]; c != nil {
+	if c := p.buffers[size]; c != nil {
 		c.Put(buf[:0])
 	}
 }
 
 // getBuf gets a chunk from reuse pool or creates a new one if reuse failed.
-func getBuf(size int) []byte {
-	if size >= config.PooledSize {
-		if c := buffers[size]; c != nil {
+func (p *Pool) getBuf(size int) []byte {
+	if size >= p.config.PooledSize {
+		if c := p.buffers[size]; c != nil {
 			v := c.Get()
 			if v != nil {
 				return v.([]byte)
@@ -70,8 +97,49 @@ type Buffer struct {
 	// Buf is the current chunk that can be used for serialization.
 	Buf []byte
 
+	// Pool is used to allocate and reuse the chunks, the default pool set up by Init if nil.
+	Pool *Pool
+
 	toPool []byte
 	bufs   [][]byte
+
+	out       io.Writer // Output for the completed chunks, see SetOutput.
+	flushSize int
+	outErr    error
+
+	maxSize int  // Maximum total size of the data, see SetMaxSize.
+	size    int  // Size of the completed chunks, including the ones written to out.
+	discard bool // Whether the data is discarded as maxSize was exceeded.
+}
+
+// pool returns the pool of the chunks.
+func (b *Buffer) pool() *Pool {
+	if b.Pool != nil {
+		return b.Pool
+	}
+	return defaultPool.Load()
+}
+
+// SetOutput makes the buffer write the completed chunks to out as soon as their total size
+// reaches flushSize, rather than keeping them until the data is retrieved. After out returns an
+// error the completed chunks are discarded.
+func (b *Buffer) SetOutput(out io.Writer, flushSize int) {
+	b.out = out
+	b.flushSize = flushSize
+	b.outErr = nil
+}
+
+// SetMaxSize limits the total size of the data, including the data written to the output set by
+// SetOutput, to maxSize bytes; zero means no limit. The limit is checked when a new chunk is
+// needed, so the data may exceed it by up to a chunk. Once it is exceeded, the data, both already
+// written and appended later, is discarded, and Exceeded reports true until the buffer is reset.
+func (b *Buffer) SetMaxSize(maxSize int) {
+	b.maxSize = maxSize
+}
+
+// Exceeded returns whether the data exceeded the size set by SetMaxSize and was discarded.
+func (b *Buffer) Exceeded() bool {
+	return b.discard
 }
 
 // EnsureSpace makes sure that the current chunk contains at least s free bytes,
@@ -83,28 +151,71 @@ func (b *Buffer) EnsureSpace(s int) {
 }
 
 func (b *Buffer) ensureSpaceSlow(s int) {
+	if b.maxSize > 0 && b.size+len(b.Buf) > b.maxSize {
+		b.discard = true
+	}
+	pool := b.pool()
+	if b.discard {
+		// Drop the data, reusing the current chunk if possible.
+		for i, buf := range b.bufs {
+			pool.putBuf(buf)
+			b.bufs[i] = nil
+		}
+		b.bufs = b.bufs[:0]
+		b.size += len(b.Buf)
+		b.Buf = b.Buf[:0]
+		if cap(b.Buf) >= s {
+			return
+		}
+	}
+
 	l := len(b.Buf)
 	if l > 0 {
 		if cap(b.toPool) != cap(b.Buf) {
 			// Chunk was reallocated, toPool can be pooled.
-			putBuf(b.toPool)
+			pool.putBuf(b.toPool)
 		}
 		if cap(b.bufs) == 0 {
 			b.bufs = make([][]byte, 0, 8)
 		}
 		b.bufs = append(b.bufs, b.Buf)
+		b.size += l
 		l = cap(b.toPool) * 2
+		if b.out != nil {
+			b.flushChunks()
+		}
 	} else {
-		l = config.StartSize
+		l = pool.config.StartSize
 	}
 
-	if l > config.MaxSize {
-		l = config.MaxSize
+	if l > pool.config.MaxSize {
+		l = pool.config.MaxSize
 	}
-	b.Buf = getBuf(l)
+	b.Buf = pool.getBuf(l)
 	b.toPool = b.Buf
 }
 
+// flushChunks writes the completed chunks to the output if their size reaches the threshold.
+func (b *Buffer) flushChunks() {
+	size := 0
+	for _, buf := range b.bufs {
+		size += len(buf)
+	}
+	if size < b.flushSize {
+		return
+	}
+
+	pool := b.pool()
+	for i, buf := range b.bufs {
+		if b.outErr == nil {
+			_, b.outErr = b.out.Write(buf)
+		}
+		pool.putBuf(buf)
+		b.bufs[i] = nil
+	}
+	b.bufs = b.bufs[:0]
+}
+
 // AppendByte appends a single byte to buffer.
 func (b *Buffer) AppendByte(data byte) {
 	b.EnsureSpace(1)
@@ -166,6 +277,13 @@ func (b *Buffer) Size() int {
 	return size
 }
 
+// Offset returns the number of bytes appended since the buffer was created or reset, including
+// the ones already written to the output set by SetOutput or discarded after exceeding the size
+// set by SetMaxSize.
+func (b *Buffer) Offset() int {
+	return b.size + len(b.Buf)
+}
+
 // DumpTo outputs the contents of a buffer to a writer and resets the buffer.
 func (b *Buffer) DumpTo(w io.Writer) (written int, err error) {
 	bufs := net.Buffers(b.bufs)
@@ -174,18 +292,36 @@ func (b *Buffer) DumpTo(w io.Writer) (written int, err error) {
 	}
 	n, err := bufs.WriteTo(w)
 
+	pool := b.pool()
 	for _, buf := range b.bufs {
-		putBuf(buf)
+		pool.putBuf(buf)
 	}
-	putBuf(b.toPool)
+	pool.putBuf(b.toPool)
 
 	b.bufs = nil
 	b.Buf = nil
 	b.toPool = nil
+	b.size = 0
+	b.discard = false
 
 	return int(n), err
 }
 
+// Flush writes all the contents of the buffer to the output set by SetOutput and resets the
+// buffer. It returns the first error returned by the output. If no output is set, the contents are
+// left in the buffer.
+func (b *Buffer) Flush() error {
+	if b.out == nil {
+		return nil
+	}
+	if b.outErr != nil {
+		_, _ = b.DumpTo(io.Discard)
+		return b.outErr
+	}
+	_, b.outErr = b.DumpTo(b.out)
+	return b.outErr
+}
+
 // BuildBytes creates a single byte slice with all the contents of the buffer. Data is
 // copied if it does not fit in a single chunk. You can optionally provide one byte
 // slice as argument that it will try to reuse.
@@ -194,6 +330,8 @@ func (b *Buffer) BuildBytes(reuse ...[]byte) []byte {
 		ret := b.Buf
 		b.toPool = nil
 		b.Buf = nil
+		b.size = 0
+		b.discard = false
 		return ret
 	}
 
@@ -206,17 +344,20 @@ func (b *Buffer) BuildBytes(reuse ...[]byte) []byte {
 	} else {
 		ret = make([]byte, 0, size)
 	}
+	pool := b.pool()
 	for _, buf := range b.bufs {
 		ret = append(ret, buf...)
-		putBuf(buf)
+		pool.putBuf(buf)
 	}
 
 	ret = append(ret, b.Buf...)
-	putBuf(b.toPool)
+	pool.putBuf(b.toPool)
 
 	b.bufs = nil
 	b.toPool = nil
 	b.Buf = nil
+	b.size = 0
+	b.discard = false
 
 	return ret
 }
@@ -224,6 +365,7 @@ func (b *Buffer) BuildBytes(reuse ...[]byte) []byte {
 type readCloser struct {
 	offset int
 	bufs   [][]byte
+	pool   *Pool
 }
 
 func (r *readCloser) Read(p []byte) (n int, err error) {
@@ -239,7 +381,7 @@ func (r *readCloser) Read(p []byte) (n int, err error) {
 			r.bufs = r.bufs[1:]
 
 			// We can release this buffer.
-			putBuf(buf)
+			r.pool.putBuf(buf)
 		} else {
 			r.offset += x
 		}
@@ -258,7 +400,7 @@ func (r *readCloser) Read(p []byte) (n int, err error) {
 func (r *readCloser) Close() error {
 	// Release all remaining buffers.
 	for _, buf := range r.bufs {
-		putBuf(buf)
+		r.pool.putBuf(buf)
 	}
 	// In case Close gets called multiple times.
 	r.bufs = nil
@@ -268,11 +410,13 @@ func (r *readCloser) Close() error {
 
 // ReadCloser creates an io.ReadCloser with all the contents of the buffer.
 func (b *Buffer) ReadCloser() io.ReadCloser {
-	ret := &readCloser{0, append(b.bufs, b.Buf)}
+	ret := &readCloser{0, append(b.bufs, b.Buf), b.pool()}
 
 	b.bufs = nil
 	b.toPool = nil
 	b.Buf = nil
+	b.size = 0
+	b.discard = false
 
 	return ret
 }
@@ -2,6 +2,7 @@ package buffer
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -105,3 +106,154 @@ func TestReadCloser(t *testing.T) {
 		t.Errorf("DumpTo() = %v; want %v", n, len(want))
 	}
 }
+
+func TestSetOutput(t *testing.T) {
+	var b Buffer
+	var want []byte
+
+	out := &bytes.Buffer{}
+	b.SetOutput(out, 1000)
+
+	s := "test"
+	for i := 0; i < 10000; i++ {
+		b.AppendString(s)
+		want = append(want, s...)
+
+		if size := b.Size(); size > 1000+defaultPool.Load().config.MaxSize {
+			t.Fatalf("Size() = %v; want at most %v", size, 1000+defaultPool.Load().config.MaxSize)
+		}
+	}
+	if out.Len() == 0 {
+		t.Errorf("no data written before Flush()")
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("Flush(): got %v; want %v", got, want)
+	}
+}
+
+func TestFlushNoOutput(t *testing.T) {
+	var b Buffer
+	b.AppendString("test")
+	if err := b.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if got := string(b.BuildBytes()); got != "test" {
+		t.Errorf("BuildBytes() = %q after Flush(); want %q", got, "test")
+	}
+}
+
+type errorWriter struct {
+	n int
+}
+
+func (w *errorWriter) Write(data []byte) (int, error) {
+	w.n++
+	return 0, errors.New("write error")
+}
+
+func TestSetOutputError(t *testing.T) {
+	var b Buffer
+
+	out := &errorWriter{}
+	b.SetOutput(out, 0)
+	for i := 0; i < 10000; i++ {
+		b.AppendString("test")
+	}
+
+	if err := b.Flush(); err == nil {
+		t.Errorf("Flush() expected error")
+	}
+	if out.n != 1 {
+		t.Errorf("output written %v times after error; want 1", out.n)
+	}
+}
+
+func TestSetMaxSize(t *testing.T) {
+	var b Buffer
+
+	b.SetMaxSize(1000)
+	for i := 0; i < 100000; i++ {
+		b.AppendString("test")
+	}
+
+	if !b.Exceeded() {
+		t.Errorf("Exceeded() = false after exceeding the limit")
+	}
+	if size := b.Size(); size > 1000+defaultPool.Load().config.MaxSize {
+		t.Errorf("Size() = %v after exceeding the limit; want at most %v", size, 1000+defaultPool.Load().config.MaxSize)
+	}
+
+	b.BuildBytes()
+	b.AppendString("test")
+	if b.Exceeded() {
+		t.Errorf("Exceeded() = true after reset")
+	}
+	if got := string(b.BuildBytes()); got != "test" {
+		t.Errorf("BuildBytes() = %q after reset; want %q", got, "test")
+	}
+}
+
+func TestPool(t *testing.T) {
+	p := NewPool(PoolConfig{StartSize: 16, PooledSize: 32, MaxSize: 64})
+	b := Buffer{Pool: p}
+	var want []byte
+
+	s := "test"
+	for i := 0; i < 1000; i++ {
+		b.AppendString(s)
+		want = append(want, s...)
+		if c := cap(b.Buf); c > 64 {
+			t.Fatalf("chunk of %v bytes allocated; want at most 64", c)
+		}
+	}
+	if c := cap(b.bufs[0]); c != 16 {
+		t.Errorf("first chunk of %v bytes allocated; want 16", c)
+	}
+
+	got := b.BuildBytes()
+	if !bytes.Equal(got, want) {
+		t.Errorf("BuildBytes() = %v; want %v", got, want)
+	}
+	if c := p.getBuf(64); cap(c) != 64 {
+		t.Errorf("getBuf(64) = %v bytes; want 64", cap(c))
+	}
+}
+
+func TestOffset(t *testing.T) {
+	var b Buffer
+	var out bytes.Buffer
+
+	b.SetOutput(&out, 0)
+	for i := 0; i < 1000; i++ {
+		b.AppendString("test")
+		if got := b.Offset(); got != (i+1)*4 {
+			t.Fatalf("Offset() = %v; want %v", got, (i+1)*4)
+		}
+	}
+	if out.Len() == 0 {
+		t.Errorf("no data written to the output")
+	}
+}
+
+func TestNewPoolDefaults(t *testing.T) {
+	for _, cfg := range []PoolConfig{
+		{},
+		{MaxSize: 65536},
+		{StartSize: -1, PooledSize: -1, MaxSize: -1},
+	} {
+		p := NewPool(cfg)
+		if p.config.StartSize <= 0 || p.config.PooledSize <= 0 || p.config.MaxSize <= 0 {
+			t.Errorf("NewPool(%+v) config = %+v; want positive sizes", cfg, p.config)
+		}
+
+		b := Buffer{Pool: p}
+		b.AppendString("test")
+		if got := string(b.BuildBytes()); got != "test" {
+			t.Errorf("BuildBytes() = %q with NewPool(%+v); want %q", got, cfg, "test")
+		}
+	}
+}
@@ -24,6 +24,8 @@ var noStdMarshalers = flag.Bool("no_std_marshalers", false, "don't generate Mars
 var omitEmpty = flag.Bool("omit_empty", false, "omit empty fields by default")
 var allStructs = flag.Bool("all", false, "generate marshaler/unmarshalers for all structs in a file")
 var simpleBytes = flag.Bool("byte", false, "use simple bytes instead of Base64Bytes for slice of bytes")
+var bytesEncoding = flag.String("bytes_encoding", "", "encoding of slices of bytes: base64, base64url, base64raw or hex (default base64)")
+var sortMapKeys = flag.Bool("sort_map_keys", false, "encode map keys in sorted order")
 var leaveTemps = flag.Bool("leave_temps", false, "do not delete temporary files")
 var stubs = flag.Bool("stubs", false, "only generate stubs for marshaler/unmarshaler funcs")
 var noformat = flag.Bool("noformat", false, "do not run 'gofmt -w' on output file")
@@ -85,6 +87,8 @@ func generate(fname string) (err error) {
 		StubsOnly:                *stubs,
 		NoFormat:                 *noformat,
 		SimpleBytes:              *simpleBytes,
+		BytesEncoding:            *bytesEncoding,
+		SortMapKeys:              *sortMapKeys,
 	}
 
 	if err := g.Run(); err != nil {
@@ -57,10 +57,36 @@ var customDecoders = map[string]string{
 	"json.Number": "in.JsonNumber()",
 }
 
+// bytesDecoders are the lexer methods for the encodings of byte slices and arrays, see
+// bytesEncoders.
+var bytesDecoders = map[string]string{
+	"base64":    "in.Bytes()",
+	"base64url": "in.Base64URLBytes()",
+	"base64raw": "in.Base64RawBytes()",
+	"hex":       "in.HexBytes()",
+}
+
+// bigDecoders are used for math/big types instead of their unmarshaler interfaces, which do not
+// accept all the JSON numbers.
+var bigDecoders = map[string]string{
+	"Int":   "in.BigInt()",
+	"Float": "in.BigFloat()",
+	"Rat":   "in.BigRat()",
+}
+
 // genTypeDecoder generates decoding code for the type t, but uses unmarshaler interface if implemented by t.
 func (g *Generator) genTypeDecoder(t reflect.Type, out string, tags fieldTags, indent int) error {
 	ws := strings.Repeat("  ", indent)
 
+	if dec := bigDecoders[t.Name()]; dec != "" && t.PkgPath() == "math/big" {
+		fmt.Fprintln(g.out, ws+"if in.IsNull() {")
+		fmt.Fprintln(g.out, ws+"  in.Skip()")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  ("+out+").Set("+dec+")")
+		fmt.Fprintln(g.out, ws+"}")
+		return nil
+	}
+
 	unmarshalerIface := reflect.TypeOf((*easyjson.Unmarshaler)(nil)).Elem()
 	if reflect.PtrTo(t).Implements(unmarshalerIface) {
 		fmt.Fprintln(g.out, ws+"if in.IsNull() {")
@@ -163,10 +189,10 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 			fmt.Fprintln(g.out, ws+"  in.Skip()")
 			fmt.Fprintln(g.out, ws+"  "+out+" = nil")
 			fmt.Fprintln(g.out, ws+"} else {")
-			if g.simpleBytes {
+			if enc := g.bytesEncodingFor(tags); enc == "" {
 				fmt.Fprintln(g.out, ws+"  "+out+" = []byte(in.String())")
 			} else {
-				fmt.Fprintln(g.out, ws+"  "+out+" = in.Bytes()")
+				fmt.Fprintln(g.out, ws+"  "+out+" = "+bytesDecoders[enc])
 			}
 
 			fmt.Fprintln(g.out, ws+"}")
@@ -214,7 +240,11 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 			fmt.Fprintln(g.out, ws+"if in.IsNull() {")
 			fmt.Fprintln(g.out, ws+"  in.Skip()")
 			fmt.Fprintln(g.out, ws+"} else {")
-			fmt.Fprintln(g.out, ws+"  copy("+out+"[:], in.Bytes())")
+			if enc := g.bytesEncodingFor(tags); enc == "" {
+				fmt.Fprintln(g.out, ws+"  copy("+out+"[:], in.Bytes())")
+			} else {
+				fmt.Fprintln(g.out, ws+"  copy("+out+"[:], "+bytesDecoders[enc]+")")
+			}
 			fmt.Fprintln(g.out, ws+"}")
 
 		} else {
@@ -60,6 +60,8 @@ type fieldTags struct {
 	required    bool
 	intern      bool
 	noCopy      bool
+
+	bytesEncoding string // Encoding of byte slices and arrays, one of the bytesEncoders keys.
 }
 
 // parseFieldTags parses the json field tag into a structure.
@@ -84,16 +86,58 @@ func parseFieldTags(f reflect.StructField) fieldTags {
 			ret.intern = true
 		case s == "nocopy":
 			ret.noCopy = true
+		case bytesEncoders[s] != "":
+			ret.bytesEncoding = s
 		}
 	}
 
 	return ret
 }
 
+// bytesEncoders are the writer methods for the encodings of byte slices and arrays that can be
+// set by a field tag or SetBytesEncoding.
+var bytesEncoders = map[string]string{
+	"base64":    "out.Base64Bytes(%v)",
+	"base64url": "out.Base64URLBytes(%v)",
+	"base64raw": "out.Base64RawBytes(%v)",
+	"hex":       "out.HexBytes(%v)",
+}
+
+// bytesEncodingFor returns the encoding of byte slices and arrays for a field with the given tags,
+// or "" if they are encoded as simple strings.
+func (g *Generator) bytesEncodingFor(tags fieldTags) string {
+	switch {
+	case tags.bytesEncoding != "":
+		return tags.bytesEncoding
+	case g.bytesEncoding != "":
+		return g.bytesEncoding
+	case g.simpleBytes:
+		return ""
+	}
+	return "base64"
+}
+
+// bigEncoders are used for math/big types instead of their marshaler interfaces, so that the
+// values are encoded as exact JSON numbers rather than strings.
+var bigEncoders = map[string]string{
+	"Int":   "out.BigInt(%v)",
+	"Float": "out.BigFloat(%v)",
+	"Rat":   "out.BigRat(%v)",
+}
+
 // genTypeEncoder generates code that encodes in of type t into the writer, but uses marshaler interface if implemented by t.
 func (g *Generator) genTypeEncoder(t reflect.Type, in string, tags fieldTags, indent int, assumeNonEmpty bool) error {
 	ws := strings.Repeat("  ", indent)
 
+	if enc := bigEncoders[t.Name()]; enc != "" && t.PkgPath() == "math/big" {
+		ptr := "&(" + in + ")"
+		if strings.HasPrefix(in, "*") {
+			ptr = in[1:]
+		}
+		fmt.Fprintf(g.out, ws+enc+"\n", ptr)
+		return nil
+	}
+
 	marshalerIface := reflect.TypeOf((*easyjson.Marshaler)(nil)).Elem()
 	if reflect.PtrTo(t).Implements(marshalerIface) {
 		fmt.Fprintln(g.out, ws+"("+in+").MarshalEasyJSON(out)")
@@ -146,10 +190,10 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 		vVar := g.uniqueVarName()
 
 		if t.Elem().Kind() == reflect.Uint8 && elem.Name() == "uint8" {
-			if g.simpleBytes {
+			if enc := g.bytesEncodingFor(tags); enc == "" {
 				fmt.Fprintln(g.out, ws+"out.String(string("+in+"))")
 			} else {
-				fmt.Fprintln(g.out, ws+"out.Base64Bytes("+in+")")
+				fmt.Fprintln(g.out, ws+fmt.Sprintf(bytesEncoders[enc], in))
 			}
 		} else {
 			if !assumeNonEmpty {
@@ -159,18 +203,19 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 			} else {
 				fmt.Fprintln(g.out, ws+"{")
 			}
-			fmt.Fprintln(g.out, ws+"  out.RawByte('[')")
+			fmt.Fprintln(g.out, ws+"  out.RawOpen('[')")
 			fmt.Fprintln(g.out, ws+"  for "+iVar+", "+vVar+" := range "+in+" {")
 			fmt.Fprintln(g.out, ws+"    if "+iVar+" > 0 {")
 			fmt.Fprintln(g.out, ws+"      out.RawByte(',')")
 			fmt.Fprintln(g.out, ws+"    }")
+			fmt.Fprintln(g.out, ws+"    out.RawIndent()")
 
 			if err := g.genTypeEncoder(elem, vVar, tags, indent+2, false); err != nil {
 				return err
 			}
 
 			fmt.Fprintln(g.out, ws+"  }")
-			fmt.Fprintln(g.out, ws+"  out.RawByte(']')")
+			fmt.Fprintln(g.out, ws+"  out.RawClose(']')")
 			fmt.Fprintln(g.out, ws+"}")
 		}
 
@@ -179,24 +224,25 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 		iVar := g.uniqueVarName()
 
 		if t.Elem().Kind() == reflect.Uint8 && elem.Name() == "uint8" {
-			if g.simpleBytes {
+			if enc := g.bytesEncodingFor(tags); enc == "" {
 				fmt.Fprintln(g.out, ws+"out.String(string("+in+"[:]))")
 			} else {
-				fmt.Fprintln(g.out, ws+"out.Base64Bytes("+in+"[:])")
+				fmt.Fprintln(g.out, ws+fmt.Sprintf(bytesEncoders[enc], in+"[:]"))
 			}
 		} else {
-			fmt.Fprintln(g.out, ws+"out.RawByte('[')")
+			fmt.Fprintln(g.out, ws+"out.RawOpen('[')")
 			fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
 			fmt.Fprintln(g.out, ws+"  if "+iVar+" > 0 {")
 			fmt.Fprintln(g.out, ws+"    out.RawByte(',')")
 			fmt.Fprintln(g.out, ws+"  }")
+			fmt.Fprintln(g.out, ws+"  out.RawIndent()")
 
 			if err := g.genTypeEncoder(elem, "("+in+")["+iVar+"]", tags, indent+1, false); err != nil {
 				return err
 			}
 
 			fmt.Fprintln(g.out, ws+"}")
-			fmt.Fprintln(g.out, ws+"out.RawByte(']')")
+			fmt.Fprintln(g.out, ws+"out.RawClose(']')")
 		}
 
 	case reflect.Struct:
@@ -235,10 +281,11 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 		} else {
 			fmt.Fprintln(g.out, ws+"{")
 		}
-		fmt.Fprintln(g.out, ws+"  out.RawByte('{')")
+		fmt.Fprintf(g.out, ws+"  out.RawOpenMap(%v)\n", g.sortMapKeys)
 		fmt.Fprintln(g.out, ws+"  "+tmpVar+"First := true")
 		fmt.Fprintln(g.out, ws+"  for "+tmpVar+"Name, "+tmpVar+"Value := range "+in+" {")
 		fmt.Fprintln(g.out, ws+"    if "+tmpVar+"First { "+tmpVar+"First = false } else { out.RawByte(',') }")
+		fmt.Fprintln(g.out, ws+"    out.RawIndent()")
 
 		// NOTE: extra check for TextMarshaler. It overrides default methods.
 		if reflect.PtrTo(key).Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
@@ -251,14 +298,14 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 			}
 		}
 
-		fmt.Fprintln(g.out, ws+"    out.RawByte(':')")
+		fmt.Fprintln(g.out, ws+"    out.RawColon()")
 
 		if err := g.genTypeEncoder(t.Elem(), tmpVar+"Value", tags, indent+2, false); err != nil {
 			return err
 		}
 
 		fmt.Fprintln(g.out, ws+"  }")
-		fmt.Fprintln(g.out, ws+"  out.RawByte('}')")
+		fmt.Fprintln(g.out, ws+"  out.RawClose('}')")
 		fmt.Fprintln(g.out, ws+"}")
 
 	case reflect.Interface:
@@ -349,18 +396,18 @@ func (g *Generator) genStructFieldEncoder(t reflect.Type, f reflect.StructField,
 			if !noOmitEmpty {
 				fmt.Fprintln(g.out, "      first = false")
 			}
-			fmt.Fprintln(g.out, "      out.RawString(prefix[1:])")
+			fmt.Fprintln(g.out, "      out.RawField(prefix[1:])")
 		} else {
 			fmt.Fprintln(g.out, "    if first {")
 			fmt.Fprintln(g.out, "      first = false")
-			fmt.Fprintln(g.out, "      out.RawString(prefix[1:])")
+			fmt.Fprintln(g.out, "      out.RawField(prefix[1:])")
 			fmt.Fprintln(g.out, "    } else {")
-			fmt.Fprintln(g.out, "      out.RawString(prefix)")
+			fmt.Fprintln(g.out, "      out.RawField(prefix)")
 			fmt.Fprintln(g.out, "    }")
 		}
 	} else {
 		fmt.Fprintf(g.out, "    const prefix string = %q\n", ","+strconv.Quote(jsonName)+":")
-		fmt.Fprintln(g.out, "    out.RawString(prefix)")
+		fmt.Fprintln(g.out, "    out.RawField(prefix)")
 	}
 
 	if err := g.genTypeEncoder(f.Type, "in."+f.Name, tags, 2, !noOmitEmpty); err != nil {
@@ -407,7 +454,7 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 	typ := g.getType(t)
 
 	fmt.Fprintln(g.out, "func "+fname+"(out *jwriter.Writer, in "+typ+") {")
-	fmt.Fprintln(g.out, "  out.RawByte('{')")
+	fmt.Fprintln(g.out, "  out.RawOpen('{')")
 	fmt.Fprintln(g.out, "  first := true")
 	fmt.Fprintln(g.out, "  _ = first")
 
@@ -433,7 +480,7 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 		}
 	}
 
-	fmt.Fprintln(g.out, "  out.RawByte('}')")
+	fmt.Fprintln(g.out, "  out.RawClose('}')")
 	fmt.Fprintln(g.out, "}")
 
 	return nil
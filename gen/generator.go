@@ -38,6 +38,8 @@ type Generator struct {
 	disallowUnknownFields    bool
 	fieldNamer               FieldNamer
 	simpleBytes              bool
+	bytesEncoding            string
+	sortMapKeys              bool
 	skipMemberNameUnescaping bool
 
 	// package path to local alias map for tracking imports
@@ -133,6 +135,18 @@ func (g *Generator) SimpleBytes() {
 	g.simpleBytes = true
 }
 
+// SetBytesEncoding sets the default encoding of byte slices and arrays: "base64", "base64url"
+// (URL-safe alphabet without padding), "base64raw" (standard alphabet without padding) or "hex".
+// Fields can override it with the same tag options.
+func (g *Generator) SetBytesEncoding(enc string) {
+	g.bytesEncoding = enc
+}
+
+// SortMapKeys makes map encoders emit the members sorted by their names, as encoding/json does.
+func (g *Generator) SortMapKeys() {
+	g.sortMapKeys = true
+}
+
 // addTypes requests to generate encoding/decoding funcs for the given type.
 func (g *Generator) addType(t reflect.Type) {
 	if g.typesSeen[t] {
@@ -197,6 +211,9 @@ func (g *Generator) printHeader() {
 
 // Run runs the generator and outputs generated code to out.
 func (g *Generator) Run(out io.Writer) error {
+	if g.bytesEncoding != "" && bytesEncoders[g.bytesEncoding] == "" {
+		return fmt.Errorf("unknown bytes encoding %q", g.bytesEncoding)
+	}
 	g.out = &bytes.Buffer{}
 
 	for len(g.typesUnseen) > 0 {
@@ -3,9 +3,9 @@ package easyjson
 
 import (
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strconv"
+	"sync"
 	"unsafe"
 
 	"github.com/mailru/easyjson/jlexer"
@@ -47,26 +47,101 @@ func isNilInterface(i interface{}) bool {
 	return (*[2]uintptr)(unsafe.Pointer(&i))[1] == 0
 }
 
+// Lexers and writers used by the helpers are pooled, so that they are not allocated per call.
+var (
+	lexerPool  = sync.Pool{New: func() interface{} { return new(jlexer.Lexer) }}
+	writerPool = sync.Pool{New: func() interface{} { return new(jwriter.Writer) }}
+)
+
+func getLexer(data []byte) *jlexer.Lexer {
+	l := lexerPool.Get().(*jlexer.Lexer)
+	l.Reset(data)
+	return l
+}
+
+// putLexer returns a lexer to the pool, clearing the options that the unmarshalers may have set.
+func putLexer(l *jlexer.Lexer) {
+	l.ResetAll(nil)
+	lexerPool.Put(l)
+}
+
+func getWriter() *jwriter.Writer {
+	return writerPool.Get().(*jwriter.Writer)
+}
+
+// putWriter returns a writer to the pool, its buffer must be already released by DumpTo or
+// BuildBytes.
+func putWriter(w *jwriter.Writer) {
+	*w = jwriter.Writer{}
+	writerPool.Put(w)
+}
+
+// MarshalOptions configures the marshaling helpers that are its methods. The zero value behaves
+// the same as the package-level helpers.
+type MarshalOptions struct {
+	// MaxSize limits the size of the output, see jwriter.Writer.SetMaxSize; zero means no limit.
+	// The helpers return a *jwriter.MaxSizeError if it is exceeded.
+	MaxSize int
+}
+
+// getWriter gets a writer from the pool, configured with the options.
+func (o MarshalOptions) getWriter() *jwriter.Writer {
+	w := getWriter()
+	if o.MaxSize > 0 {
+		w.SetMaxSize(o.MaxSize)
+	}
+	return w
+}
+
 // Marshal returns data as a single byte slice. Method is suboptimal as the data is likely to be copied
 // from a chain of smaller chunks.
 func Marshal(v Marshaler) ([]byte, error) {
+	return MarshalOptions{}.Marshal(v)
+}
+
+// Marshal is like the package-level Marshal, with the options applied.
+func (o MarshalOptions) Marshal(v Marshaler) ([]byte, error) {
 	if isNilInterface(v) {
 		return nullBytes, nil
 	}
 
-	w := jwriter.Writer{}
-	v.MarshalEasyJSON(&w)
+	w := o.getWriter()
+	defer putWriter(w)
+	v.MarshalEasyJSON(w)
+	return w.BuildBytes()
+}
+
+// MarshalIndent is like Marshal but applies indentation to format the output, the same way as
+// json.MarshalIndent does.
+func MarshalIndent(v Marshaler, prefix, indent string) ([]byte, error) {
+	if isNilInterface(v) {
+		return nullBytes, nil
+	}
+
+	w := getWriter()
+	defer putWriter(w)
+	w.SetIndent(prefix, indent)
+	v.MarshalEasyJSON(w)
 	return w.BuildBytes()
 }
 
 // MarshalToWriter marshals the data to an io.Writer.
 func MarshalToWriter(v Marshaler, w io.Writer) (written int, err error) {
+	return MarshalOptions{}.MarshalToWriter(v, w)
+}
+
+// MarshalToWriter is like the package-level MarshalToWriter, with the options applied.
+func (o MarshalOptions) MarshalToWriter(v Marshaler, w io.Writer) (written int, err error) {
 	if isNilInterface(v) {
 		return w.Write(nullBytes)
 	}
 
-	jw := jwriter.Writer{}
-	v.MarshalEasyJSON(&jw)
+	jw := o.getWriter()
+	defer putWriter(jw)
+	v.MarshalEasyJSON(jw)
+	if err := jw.Err(); err != nil {
+		return 0, err
+	}
 	return jw.DumpTo(w)
 }
 
@@ -75,6 +150,12 @@ func MarshalToWriter(v Marshaler, w io.Writer) (written int, err error) {
 // false if an error occurred before any http.ResponseWriter methods were actually
 // invoked (in this case a 500 reply is possible).
 func MarshalToHTTPResponseWriter(v Marshaler, w http.ResponseWriter) (started bool, written int, err error) {
+	return MarshalOptions{}.MarshalToHTTPResponseWriter(v, w)
+}
+
+// MarshalToHTTPResponseWriter is like the package-level MarshalToHTTPResponseWriter, with the
+// options applied.
+func (o MarshalOptions) MarshalToHTTPResponseWriter(v Marshaler, w http.ResponseWriter) (started bool, written int, err error) {
 	if isNilInterface(v) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Content-Length", strconv.Itoa(len(nullBytes)))
@@ -82,10 +163,11 @@ func MarshalToHTTPResponseWriter(v Marshaler, w http.ResponseWriter) (started bo
 		return true, written, err
 	}
 
-	jw := jwriter.Writer{}
-	v.MarshalEasyJSON(&jw)
-	if jw.Error != nil {
-		return false, 0, jw.Error
+	jw := o.getWriter()
+	defer putWriter(jw)
+	v.MarshalEasyJSON(jw)
+	if err := jw.Err(); err != nil {
+		return false, 0, err
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Length", strconv.Itoa(jw.Size()))
@@ -97,18 +179,18 @@ func MarshalToHTTPResponseWriter(v Marshaler, w http.ResponseWriter) (started bo
 
 // Unmarshal decodes the JSON in data into the object.
 func Unmarshal(data []byte, v Unmarshaler) error {
-	l := jlexer.Lexer{Data: data}
-	v.UnmarshalEasyJSON(&l)
+	l := getLexer(data)
+	defer putLexer(l)
+	v.UnmarshalEasyJSON(l)
 	return l.Error()
 }
 
-// UnmarshalFromReader reads all the data in the reader and decodes as JSON into the object.
+// UnmarshalFromReader decodes JSON from the reader into the object. The data is read
+// incrementally, so only the currently processed part of the input is held in memory.
 func UnmarshalFromReader(r io.Reader, v Unmarshaler) error {
-	data, err := ioutil.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	l := jlexer.Lexer{Data: data}
-	v.UnmarshalEasyJSON(&l)
+	l := getLexer(nil)
+	defer putLexer(l)
+	l.Reader = r
+	v.UnmarshalEasyJSON(l)
 	return l.Error()
 }
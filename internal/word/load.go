@@ -0,0 +1,24 @@
+// This file will only be included to the build if neither
+// easyjson_nounsafe nor appengine build tag is set, and the target
+// supports unaligned memory access. See README notes for more details.
+
+//go:build !easyjson_nounsafe && !appengine && (386 || amd64 || arm64 || ppc64le)
+// +build !easyjson_nounsafe
+// +build !appengine
+// +build 386 amd64 arm64 ppc64le
+
+package word
+
+import (
+	"unsafe"
+)
+
+// Load reads 8 bytes of data starting at i as a little-endian word with a single load.
+func Load(data []byte, i int) uint64 {
+	return *(*uint64)(unsafe.Pointer(&data[i]))
+}
+
+// LoadString reads 8 bytes of s starting at i as a little-endian word with a single load.
+func LoadString(s string, i int) uint64 {
+	return *(*uint64)(unsafe.Add(unsafe.Pointer(unsafe.StringData(s)), i))
+}
@@ -0,0 +1,24 @@
+// This file is included to the build if any of the buildtags below
+// are defined, or the target does not support unaligned memory access.
+// Refer to README notes for more details.
+
+//go:build easyjson_nounsafe || appengine || !(386 || amd64 || arm64 || ppc64le)
+// +build easyjson_nounsafe appengine !386,!amd64,!arm64,!ppc64le
+
+package word
+
+import (
+	"encoding/binary"
+)
+
+// Load reads 8 bytes of data starting at i as a little-endian word.
+func Load(data []byte, i int) uint64 {
+	return binary.LittleEndian.Uint64(data[i:])
+}
+
+// LoadString reads 8 bytes of s starting at i as a little-endian word.
+func LoadString(s string, i int) uint64 {
+	_ = s[i+7] // bounds check hint to compiler
+	return uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+		uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+}
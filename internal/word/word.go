@@ -0,0 +1,20 @@
+// Package word provides the helpers shared by jlexer and jwriter for scanning data 8 bytes at a
+// time.
+package word
+
+const (
+	LSB = 0x0101010101010101 // The lowest bit of every byte in a word.
+	MSB = 0x8080808080808080 // The highest bit of every byte in a word.
+)
+
+// HasLess returns a word with the highest bit set in the bytes of w that are less than n
+// (n <= 128). Only the lowest of the set bits is exact, 0 means there are no such bytes.
+func HasLess(w uint64, n byte) uint64 {
+	return (w - LSB*uint64(n)) &^ w & MSB
+}
+
+// HasByte returns a word with the highest bit set in the bytes of w that are equal to c.
+// Only the lowest of the set bits is exact, 0 means there are no such bytes.
+func HasByte(w uint64, c byte) uint64 {
+	return HasLess(w^(LSB*uint64(c)), 1)
+}
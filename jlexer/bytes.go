@@ -0,0 +1,91 @@
+package jlexer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// bytesDecoder is a binary-to-text decoder, such as base64.Encoding.
+type bytesDecoder interface {
+	DecodedLen(n int) int
+	Decode(dst, src []byte) (int, error)
+}
+
+// hexDecoder decodes hex strings.
+type hexDecoder struct{}
+
+func (hexDecoder) DecodedLen(n int) int                { return hex.DecodedLen(n) }
+func (hexDecoder) Decode(dst, src []byte) (int, error) { return hex.Decode(dst, src) }
+
+// lenientBase64 decodes base64 strings in the standard or URL-safe alphabet, with or without
+// padding.
+type lenientBase64 struct{}
+
+func (lenientBase64) DecodedLen(n int) int {
+	return base64.RawStdEncoding.DecodedLen(n)
+}
+
+func (lenientBase64) Decode(dst, src []byte) (int, error) {
+	buf := make([]byte, 0, len(src))
+	for _, c := range src {
+		switch c {
+		case '-':
+			c = '+'
+		case '_':
+			c = '/'
+		case '=', '\r', '\n':
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return base64.RawStdEncoding.Decode(dst, buf)
+}
+
+// decodeBytes reads a string literal and decodes it into a byte slice.
+func (r *Lexer) decodeBytes(dec bytesDecoder) []byte {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() || r.token.kind != TokenString {
+		r.errInvalidToken("string")
+		return nil
+	}
+	if err := r.unescapeStringToken(); err != nil {
+		r.errInvalidToken("string")
+		return nil
+	}
+	if _, ok := dec.(*base64.Encoding); ok && r.LenientBase64 {
+		dec = lenientBase64{}
+	}
+	ret := make([]byte, dec.DecodedLen(len(r.token.byteValue)))
+	n, err := dec.Decode(ret, r.token.byteValue)
+	if err != nil {
+		lexerErr := &LexerError{
+			Reason: err.Error(),
+			Offset: r.dataOffset + r.start,
+		}
+		r.locate(lexerErr)
+		r.fatalError = lexerErr
+		return nil
+	}
+
+	r.consume()
+	return ret[:n]
+}
+
+// Base64URLBytes reads a string literal and decodes it into a byte slice using the URL-safe
+// base64 alphabet without padding (base64.RawURLEncoding).
+func (r *Lexer) Base64URLBytes() []byte {
+	return r.decodeBytes(base64.RawURLEncoding)
+}
+
+// Base64RawBytes reads a string literal and base64 decodes it into a byte slice, the padding
+// being omitted (base64.RawStdEncoding).
+func (r *Lexer) Base64RawBytes() []byte {
+	return r.decodeBytes(base64.RawStdEncoding)
+}
+
+// HexBytes reads a string literal and hex decodes it into a byte slice.
+func (r *Lexer) HexBytes() []byte {
+	return r.decodeBytes(hexDecoder{})
+}
@@ -2,14 +2,37 @@ package jlexer
 
 import "fmt"
 
+// Reasons of the errors reported on exceeding the Lexer input limits.
+const (
+	ReasonMaxDepth      = "maximum nesting depth exceeded"
+	ReasonMaxStringLen  = "maximum string length exceeded"
+	ReasonMaxArrayLen   = "maximum array length exceeded"
+	ReasonMaxObjectKeys = "maximum number of object members exceeded"
+)
+
+// ReasonDuplicateKey is the reason of the error reported on a duplicate object member name if
+// Lexer.DisallowDuplicateKeys is set.
+const ReasonDuplicateKey = "duplicate object key"
+
 // LexerError implements the error interface and represents all possible errors that can be
 // generated during parsing the JSON data.
 type LexerError struct {
 	Reason string
 	Offset int
 	Data   string
+
+	Line   int    // Line of the error in the input, starting from 1; 0 if unknown.
+	Column int    // Column of the error in bytes, starting from 1.
+	Path   string // JSON path of the value being parsed, e.g. $.orders[12].price; set if Lexer.TrackPath is on.
 }
 
 func (l *LexerError) Error() string {
-	return fmt.Sprintf("parse error: %s near offset %d of '%s'", l.Reason, l.Offset, l.Data)
+	var location string
+	if l.Line > 0 {
+		location = fmt.Sprintf(" (line %d, column %d)", l.Line, l.Column)
+	}
+	if l.Path != "" {
+		location += " at " + l.Path
+	}
+	return fmt.Sprintf("parse error: %s near offset %d%s of '%s'", l.Reason, l.Offset, location, l.Data)
 }
@@ -0,0 +1,177 @@
+package jlexer
+
+import (
+	"io"
+	"strconv"
+)
+
+// Find advances the lexer to the value at the given path relative to the current value and
+// returns whether it exists. Path elements are object member names, or decimal indexes for
+// arrays; the first member with a matching name is used. Values that are not on the path are
+// skipped with SkipRecursive, and the input following the found value is not read, so after
+// reading the value the lexer should not be used to parse the rest of the document.
+//
+// The found value can then be read with any of the reading methods, e.g.
+//
+//	l := jlexer.Lexer{Data: data}
+//	if l.Find("meta", "request_id") {
+//		id := l.String()
+//	}
+func (r *Lexer) Find(path ...string) bool {
+	for _, key := range path {
+		switch {
+		case r.IsDelim('{'):
+			if !r.findKey(key) {
+				return false
+			}
+		case r.IsDelim('['):
+			if !r.findIndex(key) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return r.Ok()
+}
+
+// findKey advances the lexer to the value of the object member with the given name.
+func (r *Lexer) findKey(key string) bool {
+	r.Delim('{')
+	for !r.IsDelim('}') {
+		name := r.UnsafeFieldName(false)
+		r.WantColon()
+		if name == key {
+			return r.Ok()
+		}
+		r.SkipRecursive()
+		r.WantComma()
+	}
+	return false
+}
+
+// findIndex advances the lexer to the array item with the given index.
+func (r *Lexer) findIndex(key string) bool {
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 0 {
+		return false
+	}
+	r.Delim('[')
+	for i := 0; !r.IsDelim(']'); i++ {
+		if i == n {
+			return r.Ok()
+		}
+		r.SkipRecursive()
+		r.WantComma()
+	}
+	return false
+}
+
+// Get returns the raw value at the given path in data, or nil if there is no such value. See
+// Lexer.Find for the path format. The returned slice refers to data.
+func Get(data []byte, path ...string) ([]byte, error) {
+	r := Lexer{Data: data}
+	if !r.Find(path...) {
+		return nil, r.getError()
+	}
+	raw := r.Raw()
+	return raw, r.getError()
+}
+
+// GetMany is like Get for several paths, but reads the document only once. The raw values are
+// returned in the order of paths, with nil for the paths that do not exist. Reading stops as soon
+// as all the values are found.
+func GetMany(data []byte, paths ...[]string) ([][]byte, error) {
+	r := Lexer{Data: data}
+	ret := make([][]byte, len(paths))
+	idx := make([]int, len(paths))
+	for i := range idx {
+		idx[i] = i
+	}
+	left := len(paths)
+	r.getMany(paths, idx, 0, ret, &left)
+	return ret, r.getError()
+}
+
+// getError returns the error of Get or GetMany, reporting the end of input in the middle of the
+// document as a *LexerError.
+func (r *Lexer) getError() error {
+	if r.fatalError == io.EOF {
+		r.fatalError = nil
+		r.errParse("unexpected end of input")
+	}
+	return r.Error()
+}
+
+// getMany reads the current value, storing the values for the paths with indexes idx into ret.
+// The paths in idx are known to match up to depth.
+func (r *Lexer) getMany(paths [][]string, idx []int, depth int, ret [][]byte, left *int) {
+	var deeper []int
+	for _, i := range idx {
+		if len(paths[i]) > depth {
+			deeper = append(deeper, i)
+		}
+	}
+
+	if len(deeper) < len(idx) {
+		// Some of the paths end here: take the raw value and look for the rest in it.
+		raw := r.Raw()
+		if !r.Ok() {
+			return
+		}
+		for _, i := range idx {
+			if len(paths[i]) == depth {
+				ret[i] = raw
+				*left--
+			}
+		}
+		if len(deeper) > 0 {
+			sub := Lexer{Data: raw}
+			sub.getMany(paths, deeper, depth, ret, left)
+		}
+		return
+	}
+
+	switch {
+	case r.IsDelim('{'):
+		r.Delim('{')
+		for !r.IsDelim('}') {
+			name := r.UnsafeFieldName(false)
+			r.WantColon()
+			r.getManyMember(paths, deeper, depth, name, ret, left)
+			if *left == 0 || !r.Ok() {
+				return
+			}
+			r.WantComma()
+		}
+		r.Delim('}')
+	case r.IsDelim('['):
+		r.Delim('[')
+		for i := 0; !r.IsDelim(']'); i++ {
+			r.getManyMember(paths, deeper, depth, strconv.Itoa(i), ret, left)
+			if *left == 0 || !r.Ok() {
+				return
+			}
+			r.WantComma()
+		}
+		r.Delim(']')
+	default:
+		r.SkipRecursive()
+	}
+}
+
+// getManyMember reads an object member or array item with the given name, looking for the paths
+// with indexes idx that continue with it.
+func (r *Lexer) getManyMember(paths [][]string, idx []int, depth int, name string, ret [][]byte, left *int) {
+	var sub []int
+	for _, i := range idx {
+		if ret[i] == nil && paths[i][depth] == name {
+			sub = append(sub, i)
+		}
+	}
+	if len(sub) == 0 {
+		r.SkipRecursive()
+		return
+	}
+	r.getMany(paths, sub, depth+1, ret, left)
+}
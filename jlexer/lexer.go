@@ -11,12 +11,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
+	"math/bits"
 	"strconv"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/josharian/intern"
+
+	"github.com/mailru/easyjson/internal/word"
 )
 
 // TokenKind determines type of a token.
@@ -41,22 +45,127 @@ type token struct {
 	delimValue      byte
 }
 
+const (
+	readerChunkSize = 32 * 1024 // Minimum number of bytes requested from Reader on each refill.
+	maxEmptyReads   = 100       // Number of empty reads after which Reader is considered stuck.
+)
+
 // Lexer is a JSON lexer: it iterates over JSON tokens in a byte slice.
+//
+// If Reader is set, Data is treated as a window into the input stream: it is refilled from
+// Reader when exhausted, and the data preceding the current token is discarded. Byte slices and
+// unsafe strings returned by the lexer remain valid, as refilling never overwrites them.
 type Lexer struct {
-	Data []byte // Input data given to the lexer.
+	Data   []byte    // Input data given to the lexer.
+	Reader io.Reader // Input stream to read more data from once Data is exhausted.
 
 	start int   // Start of the current token.
 	pos   int   // Current unscanned position in the input stream.
 	token token // Last scanned token, if token.kind != TokenUndef.
 
-	firstElement bool // Whether current element is the first in array or an object.
-	wantSep      byte // A comma or a colon character, which need to occur before a token.
+	dataOffset     int   // Offset of Data in the input stream, i.e. number of bytes already discarded.
+	readErr        error // Error returned by Reader, io.EOF at the end of input.
+	linesDiscarded int   // Number of newlines in the discarded data.
+	lineStart      int   // Offset of the line containing the beginning of Data in the input stream.
+
+	firstElement  bool // Whether current element is the first in array or an object.
+	wantSep       byte // A comma or a colon character, which need to occur before a token.
+	trailingComma bool // Whether a comma was consumed while fetching the current token.
+
+	// Strict enables full RFC 8259 validation of number literals and string contents. The set of
+	// accepted documents matches json.Valid, except that invalid UTF-8 in strings is rejected.
+	Strict bool
+
+	// Lenient enables JSON5-style extensions of the input syntax: // and /* */ comments,
+	// trailing commas in arrays and objects, single-quoted strings and unquoted member names.
+	Lenient bool
+
+	// DisallowDuplicateKeys makes a member name occurring twice in the same object an error,
+	// reported as non-fatal if UseMultipleErrors is set. It applies to structs, maps and
+	// interface{} values alike, including the members passed to UnknownsUnmarshaler.
+	DisallowDuplicateKeys bool
+
+	// UseNumber makes Interface return numbers as json.Number instead of float64.
+	UseNumber bool
+
+	// UseInt64 makes Interface return integral numbers that fit into int64 as int64, and the
+	// other numbers as float64. UseNumber takes precedence over it.
+	UseInt64 bool
+
+	// LenientBase64 makes Bytes, Base64URLBytes and Base64RawBytes accept any base64 variant:
+	// the standard or URL-safe alphabet, with or without padding.
+	LenientBase64 bool
+
+	// MultipleValues allows the input to be a stream of whitespace-separated top-level values:
+	// Consumed stops at the beginning of the next value instead of requiring the end of input.
+	MultipleValues bool
+	valueStart     int // Offset of the current top-level value in the input stream.
+
+	TrackPath bool       // If we want errors to report the JSON path of the value being parsed.
+	path      []pathElem // Arrays and objects enclosing the current token.
+
+	skipping  bool // Whether the value being skipped is kept in Data, see skipRecursive.
+	skipStart int  // Offset of the value being skipped in the input stream.
+
+	nextObjects []bool // Kinds of the arrays and objects enclosing the token returned by Next, true for objects.
+	nextKey     bool   // Whether the next token returned by Next in an object is a member name.
+	nextDone    bool   // Whether Next has returned a whole top-level value.
+
+	// Limits on the input to protect against hostile data, 0 means no limit. Exceeding a limit
+	// is a fatal error even if UseMultipleErrors is set.
+	MaxDepth      int // Maximum nesting depth of arrays and objects.
+	MaxStringLen  int // Maximum length of a string literal in bytes, before unescaping.
+	MaxArrayLen   int // Maximum number of items in an array.
+	MaxObjectKeys int // Maximum number of members in an object.
 
 	UseMultipleErrors bool          // If we want to use multiple errors.
 	fatalError        error         // Fatal error occurred during lexing. It is usually a syntax error.
 	multipleErrors    []*LexerError // Semantic errors occurred during lexing. Marshalling will be continued after finding this errors.
 }
 
+// Reset prepares the lexer for parsing data from the beginning. The options are kept, while
+// Reader and all the parsing state, including the errors, are cleared. The memory allocated for
+// tracking the enclosing arrays and objects is reused, so that a pooled Lexer does not allocate.
+func (r *Lexer) Reset(data []byte) {
+	for i := range r.path {
+		r.path[i].key = nil
+	}
+	*r = Lexer{
+		Data: data,
+
+		Strict:                r.Strict,
+		Lenient:               r.Lenient,
+		DisallowDuplicateKeys: r.DisallowDuplicateKeys,
+		UseNumber:             r.UseNumber,
+		UseInt64:              r.UseInt64,
+		LenientBase64:         r.LenientBase64,
+		MultipleValues:        r.MultipleValues,
+		TrackPath:             r.TrackPath,
+		MaxDepth:              r.MaxDepth,
+		MaxStringLen:          r.MaxStringLen,
+		MaxArrayLen:           r.MaxArrayLen,
+		MaxObjectKeys:         r.MaxObjectKeys,
+		UseMultipleErrors:     r.UseMultipleErrors,
+
+		path:        r.path[:0],
+		nextObjects: r.nextObjects[:0],
+	}
+}
+
+// ResetAll is like Reset but also clears the options, so that a lexer can be reused for unrelated
+// input without inheriting the options set by its previous user.
+func (r *Lexer) ResetAll(data []byte) {
+	for i := range r.path {
+		r.path[i].key = nil
+	}
+	*r = Lexer{
+		Data: data,
+
+		path:        r.path[:0],
+		nextObjects: r.nextObjects[:0],
+	}
+}
+
 // FetchToken scans the input for the next token.
 func (r *Lexer) FetchToken() {
 	r.token.kind = TokenUndef
@@ -70,94 +179,279 @@ func (r *Lexer) FetchToken() {
 	}
 	// Determine the type of a token by skipping whitespace and reading the
 	// first character.
-	for _, c := range r.Data[r.pos:] {
-		switch c {
-		case ':', ',':
-			if r.wantSep == c {
+	r.trailingComma = false
+scan:
+	for {
+		for _, c := range r.Data[r.pos:] {
+			switch c {
+			case ':', ',':
+				if r.wantSep == c {
+					r.pos++
+					r.start++
+					r.wantSep = 0
+					r.trailingComma = c == ','
+				} else {
+					r.errSyntax()
+				}
+
+			case ' ', '\t', '\r', '\n':
 				r.pos++
 				r.start++
+
+			case '/':
+				if !r.Lenient {
+					r.errSyntax()
+					return
+				}
+				r.skipComment()
+				if !r.Ok() {
+					return
+				}
+				continue scan
+
+			case '"', '\'':
+				if c == '\'' && !r.Lenient {
+					r.errSyntax()
+				}
+				r.beginValue()
+
+				r.token.kind = TokenString
+				r.fetchString()
+				return
+
+			case '{', '[':
+				r.beginValue()
+				r.firstElement = true
+				r.token.kind = TokenDelim
+				r.token.delimValue = r.Data[r.pos]
+				if r.tracking() {
+					r.pushPath(r.token.delimValue == '[')
+				}
+				r.pos++
+				return
+
+			case '}', ']':
+				if !r.firstElement && (r.wantSep != ',') && !(r.Lenient && r.trailingComma) {
+					r.errSyntax()
+				}
 				r.wantSep = 0
-			} else {
-				r.errSyntax()
-			}
+				r.token.kind = TokenDelim
+				r.token.delimValue = r.Data[r.pos]
+				if len(r.path) > 0 {
+					r.popPath()
+				}
+				r.pos++
+				return
 
-		case ' ', '\t', '\r', '\n':
-			r.pos++
-			r.start++
+			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
+				r.beginValue()
+				r.token.kind = TokenNumber
+				r.fetchNumber()
+				return
 
-		case '"':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+			case 'n':
+				r.beginValue()
 
-			r.token.kind = TokenString
-			r.fetchString()
-			return
+				r.token.kind = TokenNull
+				if r.Lenient {
+					r.fetchIdentifier()
+					return
+				}
+				r.fetchNull()
+				return
 
-		case '{', '[':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
-			r.firstElement = true
-			r.token.kind = TokenDelim
-			r.token.delimValue = r.Data[r.pos]
-			r.pos++
-			return
+			case 't':
+				r.beginValue()
+
+				r.token.kind = TokenBool
+				r.token.boolValue = true
+				if r.Lenient {
+					r.fetchIdentifier()
+					return
+				}
+				r.fetchTrue()
+				return
+
+			case 'f':
+				r.beginValue()
+
+				r.token.kind = TokenBool
+				r.token.boolValue = false
+				if r.Lenient {
+					r.fetchIdentifier()
+					return
+				}
+				r.fetchFalse()
+				return
 
-		case '}', ']':
-			if !r.firstElement && (r.wantSep != ',') {
+			default:
+				if r.Lenient && isIdentifierStart(c) {
+					r.beginValue()
+					r.fetchIdentifier()
+					return
+				}
 				r.errSyntax()
+				return
 			}
-			r.wantSep = 0
-			r.token.kind = TokenDelim
-			r.token.delimValue = r.Data[r.pos]
+		}
+		if !r.fetchMore() {
+			break
+		}
+	}
+	if r.fatalError == nil {
+		r.fatalError = io.EOF
+	}
+}
+
+// isIdentifierStart returns true if the char can start an unquoted member name.
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentifierChar returns true if the char can occur in an unquoted member name.
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+// fetchIdentifier scans an unquoted identifier in lenient mode. The identifier is a member
+// name if followed by a colon, otherwise it must be one of true, false and null keywords.
+func (r *Lexer) fetchIdentifier() {
+	for {
+		for r.pos < len(r.Data) && isIdentifierChar(r.Data[r.pos]) {
 			r.pos++
-			return
+		}
+		if r.pos < len(r.Data) || !r.fetchMore() {
+			break
+		}
+	}
+	if r.pos < len(r.Data) && !isTokenEnd(r.Data[r.pos]) && r.Data[r.pos] != '/' {
+		r.errSyntax()
+		return
+	}
+	ident := r.Data[r.start:r.pos]
 
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
-			r.token.kind = TokenNumber
-			r.fetchNumber()
-			return
+	if r.colonFollows() {
+		r.token.kind = TokenString
+		r.token.byteValue = ident
+		return
+	}
 
-		case 'n':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+	switch string(ident) {
+	case "null":
+		r.token.kind = TokenNull
+	case "true":
+		r.token.kind = TokenBool
+		r.token.boolValue = true
+	case "false":
+		r.token.kind = TokenBool
+		r.token.boolValue = false
+	default:
+		r.pos = r.start
+		r.errSyntax()
+	}
+}
 
-			r.token.kind = TokenNull
-			r.fetchNull()
-			return
+// colonFollows returns true if the next character after whitespace and comments is a colon. The
+// position is left unchanged, so that the current token still ends at r.pos.
+func (r *Lexer) colonFollows() bool {
+	// Offsets are relative to r.pos, as fetchMore moves the data.
+	i := 0
+	for {
+		if r.pos+i >= len(r.Data) {
+			if !r.fetchMore() {
+				return false
+			}
+			continue
+		}
 
-		case 't':
-			if r.wantSep != 0 {
-				r.errSyntax()
+		switch c := r.Data[r.pos+i]; c {
+		case ' ', '\t', '\r', '\n':
+			i++
+		case '/':
+			n, ok := r.commentLen(i)
+			if !ok {
+				return false
 			}
+			i += n
+		default:
+			return c == ':'
+		}
+	}
+}
 
-			r.token.kind = TokenBool
-			r.token.boolValue = true
-			r.fetchTrue()
-			return
+// commentLen returns the length of the // or /* */ comment at offset i from r.pos, or false if
+// there is no complete comment there.
+func (r *Lexer) commentLen(i int) (int, bool) {
+	for r.pos+i+1 >= len(r.Data) {
+		if !r.fetchMore() {
+			return 0, false
+		}
+	}
 
-		case 'f':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+	var end []byte
+	switch r.Data[r.pos+i+1] {
+	case '/':
+		end = []byte("\n")
+	case '*':
+		end = []byte("*/")
+	default:
+		return 0, false
+	}
 
-			r.token.kind = TokenBool
-			r.token.boolValue = false
-			r.fetchFalse()
-			return
+	from := i + 2
+	for {
+		if j := bytes.Index(r.Data[r.pos+from:], end); j >= 0 {
+			return from + j + len(end) - i, true
+		}
+		if n := len(r.Data) - r.pos - len(end) + 1; n > from {
+			from = n // The terminator can only start in the last bytes.
+		}
+		if !r.fetchMore() {
+			// A line comment can end the input.
+			return len(r.Data) - r.pos - i, end[0] == '\n'
+		}
+	}
+}
 
-		default:
-			r.errSyntax()
-			return
+// skipComment skips a // or /* */ comment in lenient mode.
+func (r *Lexer) skipComment() {
+	r.ensure(2)
+	if r.pos+1 >= len(r.Data) || (r.Data[r.pos+1] != '/' && r.Data[r.pos+1] != '*') {
+		r.errSyntax()
+		return
+	}
+
+	block := r.Data[r.pos+1] == '*'
+	r.pos += 2
+	for {
+		if block {
+			if i := bytes.Index(r.Data[r.pos:], []byte("*/")); i >= 0 {
+				r.pos += i + 2
+				r.start = r.pos
+				return
+			}
+			if r.pos < len(r.Data) {
+				// Keep the last byte, it may be the first half of the terminator.
+				r.pos = len(r.Data) - 1
+			}
+		} else {
+			if i := bytes.IndexByte(r.Data[r.pos:], '\n'); i >= 0 {
+				r.pos += i + 1
+				r.start = r.pos
+				return
+			}
+			r.pos = len(r.Data)
+		}
+		r.start = r.pos
+		if !r.fetchMore() {
+			break
 		}
 	}
-	r.fatalError = io.EOF
-	return
+
+	if block {
+		r.pos = len(r.Data)
+		r.errParse("unterminated comment")
+	}
 }
 
 // isTokenEnd returns true if the char can follow a non-delimiter token
@@ -167,6 +461,7 @@ func isTokenEnd(c byte) bool {
 
 // fetchNull fetches and checks remaining bytes of null keyword.
 func (r *Lexer) fetchNull() {
+	r.ensure(5)
 	r.pos += 4
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-3] != 'u' ||
@@ -181,6 +476,7 @@ func (r *Lexer) fetchNull() {
 
 // fetchTrue fetches and checks remaining bytes of true keyword.
 func (r *Lexer) fetchTrue() {
+	r.ensure(5)
 	r.pos += 4
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-3] != 'r' ||
@@ -195,6 +491,7 @@ func (r *Lexer) fetchTrue() {
 
 // fetchFalse fetches and checks remaining bytes of false keyword.
 func (r *Lexer) fetchFalse() {
+	r.ensure(6)
 	r.pos += 5
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-4] != 'a' ||
@@ -215,38 +512,110 @@ func (r *Lexer) fetchNumber() {
 	hasDot := false
 
 	r.pos++
-	for i, c := range r.Data[r.pos:] {
-		switch {
-		case c >= '0' && c <= '9':
-			afterE = false
-		case c == '.' && !hasDot:
-			hasDot = true
-		case (c == 'e' || c == 'E') && !hasE:
-			hasE = true
-			hasDot = true
-			afterE = true
-		case (c == '+' || c == '-') && afterE:
-			afterE = false
-		default:
-			r.pos += i
-			if !isTokenEnd(c) {
-				r.errSyntax()
-			} else {
-				r.token.byteValue = r.Data[r.start:r.pos]
+	for {
+		for i, c := range r.Data[r.pos:] {
+			switch {
+			case c >= '0' && c <= '9':
+				afterE = false
+			case c == '.' && !hasDot:
+				hasDot = true
+			case (c == 'e' || c == 'E') && !hasE:
+				hasE = true
+				hasDot = true
+				afterE = true
+			case (c == '+' || c == '-') && afterE:
+				afterE = false
+			default:
+				r.pos += i
+				if !isTokenEnd(c) && !(r.Lenient && c == '/') {
+					r.errSyntax()
+				} else {
+					r.setNumber()
+				}
+				return
 			}
-			return
 		}
+
+		r.pos = len(r.Data)
+		if !r.fetchMore() {
+			break
+		}
+	}
+	r.setNumber()
+}
+
+// setNumber sets the value of the number literal token, validating it in strict mode.
+func (r *Lexer) setNumber() {
+	if r.Strict && r.invalidNumber() {
+		return
+	}
+	r.token.byteValue = r.Data[r.start:r.pos]
+}
+
+// invalidNumber reports an error and returns true if the number literal token does not match the
+// RFC 8259 grammar.
+func (r *Lexer) invalidNumber() bool {
+	if isValidNumber(r.Data[r.start:r.pos]) {
+		return false
+	}
+	r.pos = r.start
+	r.errParse("invalid number literal")
+	return true
+}
+
+// isValidNumber checks that the number literal matches the RFC 8259 grammar:
+// -? (0 | [1-9][0-9]*) (.[0-9]+)? ([eE][+-]?[0-9]+)?
+func isValidNumber(data []byte) bool {
+	i := 0
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(data) && data[i] == '0':
+		i++
+	case i < len(data) && data[i] >= '1' && data[i] <= '9':
+		i = skipDigits(data, i+1)
+	default:
+		return false
+	}
+
+	if i < len(data) && data[i] == '.' {
+		j := skipDigits(data, i+1)
+		if j == i+1 {
+			return false
+		}
+		i = j
+	}
+
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		j := skipDigits(data, i)
+		if j == i {
+			return false
+		}
+		i = j
 	}
+	return i == len(data)
+}
 
-	r.pos = len(r.Data)
-	r.token.byteValue = r.Data[r.start:]
+// skipDigits returns the position of the first non-digit character in data starting from i.
+func skipDigits(data []byte, i int) int {
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	return i
 }
 
 // findStringLen tries to scan into the string literal for ending quote char to determine required size.
 // The size will be exact if no escapes are present and may be inexact if there are escaped chars.
-func findStringLen(data []byte) (isValid bool, length int) {
+// bytes.IndexByte is used instead of the 8-bytes-per-step word.Load scan: it is vectorized and
+// several times faster on long strings (see BenchmarkEJ_LexerString_* in the benchmark module).
+func findStringLen(data []byte, quote byte) (isValid bool, length int) {
 	for {
-		idx := bytes.IndexByte(data, '"')
+		idx := bytes.IndexByte(data, quote)
 		if idx == -1 {
 			return false, len(data)
 		}
@@ -271,7 +640,17 @@ func findStringLen(data []byte) (isValid bool, length int) {
 // unescapeStringToken performs unescaping of string token.
 // if no escaping is needed, original string is returned, otherwise - a new one allocated
 func (r *Lexer) unescapeStringToken() (err error) {
-	data := r.token.byteValue
+	data, err := r.unescape(r.token.byteValue)
+	if data != nil {
+		r.token.byteValue = data
+		r.token.byteValueCloned = true
+	}
+	return err
+}
+
+// unescape decodes escape sequences in the contents of a string literal into a new slice.
+// Returns nil if there are no escape sequences.
+func (r *Lexer) unescape(data []byte) ([]byte, error) {
 	var unescapedData []byte
 
 	for {
@@ -281,13 +660,17 @@ func (r *Lexer) unescapeStringToken() (err error) {
 		}
 
 		escapedRune, escapedBytes, err := decodeEscape(data[i:])
+		if err != nil && r.Lenient && i+1 < len(data) && data[i+1] == '\'' {
+			// \' is a valid escape in single-quoted strings.
+			escapedRune, escapedBytes, err = '\'', 2, nil
+		}
 		if err != nil {
 			r.errParse(err.Error())
-			return err
+			return nil, err
 		}
 
 		if unescapedData == nil {
-			unescapedData = make([]byte, 0, len(r.token.byteValue))
+			unescapedData = make([]byte, 0, len(data))
 		}
 
 		var d [4]byte
@@ -298,11 +681,10 @@ func (r *Lexer) unescapeStringToken() (err error) {
 		data = data[i+escapedBytes:]
 	}
 
-	if unescapedData != nil {
-		r.token.byteValue = append(unescapedData, data...)
-		r.token.byteValueCloned = true
+	if unescapedData == nil {
+		return nil, nil
 	}
-	return
+	return append(unescapedData, data...), nil
 }
 
 // getu4 decodes \uXXXX from the beginning of s, returning the hex value,
@@ -376,19 +758,168 @@ func decodeEscape(data []byte) (decoded rune, bytesProcessed int, err error) {
 
 // fetchString scans a string literal token.
 func (r *Lexer) fetchString() {
+	quote := r.Data[r.pos]
 	r.pos++
 	data := r.Data[r.pos:]
 
-	isValid, length := findStringLen(data)
+	isValid, length := findStringLen(data, quote)
+	if !isValid || r.MaxStringLen > 0 || r.Strict {
+		r.fetchStringSlow(quote, isValid, length)
+		return
+	}
+	r.token.byteValue = data[:length]
+	r.pos += length + 1 // skip closing '"' as well
+}
+
+// fetchStringSlow finishes scanning a string literal that needs more input or checks against
+// MaxStringLen and Strict. isValid and length are the results of findStringLen on the data
+// available so far.
+func (r *Lexer) fetchStringSlow(quote byte, isValid bool, length int) {
+	data := r.Data[r.pos:]
+	for !isValid && (r.MaxStringLen == 0 || length <= r.MaxStringLen) && r.fetchMore() {
+		data = r.Data[r.pos:]
+		isValid, length = findStringLen(data, quote)
+	}
+	if r.MaxStringLen > 0 && length > r.MaxStringLen {
+		r.errLimit(ReasonMaxStringLen)
+		return
+	}
 	if !isValid {
 		r.pos += length
 		r.errParse("unterminated string literal")
 		return
 	}
+	if r.Strict {
+		if i, reason := r.validateString(data[:length]); reason != "" {
+			r.pos += i
+			r.errParse(reason)
+			return
+		}
+	}
 	r.token.byteValue = data[:length]
 	r.pos += length + 1 // skip closing '"' as well
 }
 
+// validateString checks the contents of a string literal for control characters, invalid
+// escape sequences and, in Strict mode, invalid UTF-8. Returns the position and the reason of
+// the first problem.
+func (r *Lexer) validateString(data []byte) (int, string) {
+	for i := 0; i < len(data); {
+		// Skip the bytes that need no checks 8 at a time.
+		for i+8 <= len(data) {
+			w := word.Load(data, i)
+			m := word.HasLess(w, 0x20) | word.HasByte(w, '\\')
+			if r.Strict {
+				m |= w & word.MSB
+			}
+			if m != 0 {
+				i += bits.TrailingZeros64(m) / 8
+				break
+			}
+			i += 8
+		}
+		if i >= len(data) {
+			break
+		}
+
+		c := data[i]
+		switch {
+		case c < 0x20:
+			return i, "invalid control character in string literal"
+		case c == '\\':
+			if i+1 >= len(data) {
+				return i, "incorrect escape symbol \\ at the end of token"
+			}
+			switch data[i+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i += 2
+			case '\'':
+				if !r.Lenient {
+					return i, "incorrectly escaped bytes"
+				}
+				i += 2
+			case 'u':
+				if getu4(data[i:]) < 0 {
+					return i, "incorrectly escaped \\uXXXX sequence"
+				}
+				i += 6
+			default:
+				return i, "incorrectly escaped bytes"
+			}
+		case c < utf8.RuneSelf || !r.Strict:
+			i++
+		default:
+			c, size := utf8.DecodeRune(data[i:])
+			if c == utf8.RuneError && size == 1 {
+				return i, "invalid UTF-8 in string literal"
+			}
+			i += size
+		}
+	}
+	return 0, ""
+}
+
+// ensure makes sure that at least n bytes are available in Data past the current position,
+// unless the end of input is reached earlier.
+func (r *Lexer) ensure(n int) {
+	for len(r.Data)-r.pos < n && r.fetchMore() {
+	}
+}
+
+// fetchMore reads the next chunk of input from Reader, if it is set. The data preceding the
+// current token is discarded, and the rest is copied into a newly allocated buffer, so slices
+// previously returned to the caller are never overwritten. Returns false if no more data is
+// available.
+func (r *Lexer) fetchMore() bool {
+	if r.Reader == nil || r.fatalError != nil {
+		return false
+	}
+	if r.readErr != nil {
+		if r.readErr != io.EOF {
+			r.fatalError = r.readErr
+		}
+		return false
+	}
+
+	from := r.start
+	if r.skipping && r.skipStart-r.dataOffset < from {
+		from = r.skipStart - r.dataOffset
+	}
+	discarded := r.Data[:from]
+	r.linesDiscarded += bytes.Count(discarded, []byte{'\n'})
+	if i := bytes.LastIndexByte(discarded, '\n'); i >= 0 {
+		r.lineStart = r.dataOffset + i + 1
+	}
+
+	keep := r.Data[from:]
+	size := readerChunkSize
+	if len(keep) >= size/2 {
+		// The current token does not fit: grow the window to keep rescanning amortized.
+		size = 2 * len(keep)
+	}
+	buf := make([]byte, len(keep), size)
+	copy(buf, keep)
+
+	r.dataOffset += from
+	r.pos -= from
+	r.start -= from
+	r.Data = buf
+
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := r.Reader.Read(buf[len(buf):cap(buf)])
+		r.Data = buf[:len(buf)+n]
+		r.readErr = err
+		if n > 0 {
+			return true
+		}
+		if err != nil {
+			return r.fetchMore()
+		}
+	}
+	r.readErr = io.ErrNoProgress
+	return r.fetchMore()
+}
+
 // scanToken scans the next token if no token is currently available in the lexer.
 func (r *Lexer) scanToken() {
 	if r.token.kind != TokenUndef || r.fatalError != nil {
@@ -420,11 +951,13 @@ func (r *Lexer) errParse(what string) {
 		} else {
 			str = string(r.Data[r.pos:r.pos+maxErrorContextLen-3]) + "..."
 		}
-		r.fatalError = &LexerError{
+		err := &LexerError{
 			Reason: what,
-			Offset: r.pos,
+			Offset: r.dataOffset + r.pos,
 			Data:   str,
 		}
+		r.locate(err)
+		r.fatalError = err
 	}
 }
 
@@ -432,6 +965,12 @@ func (r *Lexer) errSyntax() {
 	r.errParse("syntax error")
 }
 
+// errLimit reports a fatal error on exceeding one of the input limits.
+func (r *Lexer) errLimit(reason string) {
+	r.pos = r.start
+	r.errParse(reason)
+}
+
 func (r *Lexer) errInvalidToken(expected string) {
 	if r.fatalError != nil {
 		return
@@ -439,7 +978,7 @@ func (r *Lexer) errInvalidToken(expected string) {
 	if r.UseMultipleErrors {
 		r.pos = r.start
 		r.consume()
-		r.SkipRecursive()
+		r.skipRecursive(true)
 		switch expected {
 		case "[":
 			r.token.delimValue = ']'
@@ -450,7 +989,7 @@ func (r *Lexer) errInvalidToken(expected string) {
 		}
 		r.addNonfatalError(&LexerError{
 			Reason: fmt.Sprintf("expected %s", expected),
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Data:   string(r.Data[r.start:r.pos]),
 		})
 		return
@@ -462,15 +1001,18 @@ func (r *Lexer) errInvalidToken(expected string) {
 	} else {
 		str = string(r.token.byteValue[:maxErrorContextLen-3]) + "..."
 	}
-	r.fatalError = &LexerError{
+	err := &LexerError{
 		Reason: fmt.Sprintf("expected %s", expected),
-		Offset: r.pos,
+		Offset: r.dataOffset + r.pos,
 		Data:   str,
 	}
+	r.locate(err)
+	r.fatalError = err
 }
 
+// GetPos returns the current position in the input stream.
 func (r *Lexer) GetPos() int {
-	return r.pos
+	return r.dataOffset + r.pos
 }
 
 // Delim consumes a token and verifies that it is the given delimiter.
@@ -525,67 +1067,114 @@ func (r *Lexer) Skip() {
 // SkipRecursive skips next array or object completely, or just skips a single token if not
 // an array/object.
 //
-// Note: no syntax validation is performed on the skipped data.
+// The syntax of a skipped array or object is validated in the same pass, honoring the Strict
+// and Lenient modes and the input limits.
 func (r *Lexer) SkipRecursive() {
+	r.skipRecursive(false)
+}
+
+// skipRecursive implements SkipRecursive. If keep is true, the whole skipped value is kept in
+// Data while reading from Reader, so that it spans Data[r.start:r.pos] afterwards, as Raw needs;
+// otherwise the data preceding the current token can be discarded as usual.
+func (r *Lexer) skipRecursive(keep bool) {
 	r.scanToken()
-	var start, end byte
-	startPos := r.start
-
-	switch r.token.delimValue {
-	case '{':
-		start, end = '{', '}'
-	case '[':
-		start, end = '[', ']'
-	default:
+	if r.token.kind != TokenDelim || (r.token.delimValue != '{' && r.token.delimValue != '[') {
 		r.consume()
 		return
 	}
 
+	r.skipStart = r.dataOffset + r.start
+	if keep {
+		r.skipping = true
+		defer func() { r.skipping = false }()
+	}
+
+	// Kinds of the enclosing arrays and objects, true for objects.
+	stack := make([]bool, 0, 16)
+	stack = append(stack, r.token.delimValue == '{')
+	wantKey := stack[0]
 	r.consume()
 
-	level := 1
-	inQuotes := false
-	wasEscape := false
+	for len(stack) > 0 {
+		r.FetchToken()
+		if !r.Ok() {
+			break
+		}
+		top := len(stack) - 1
 
-	for i, c := range r.Data[r.pos:] {
 		switch {
-		case c == start && !inQuotes:
-			level++
-		case c == end && !inQuotes:
-			level--
-			if level == 0 {
-				r.pos += i + 1
-				if !json.Valid(r.Data[startPos:r.pos]) {
-					r.pos = len(r.Data)
-					r.fatalError = &LexerError{
-						Reason: "skipped array/object json value is invalid",
-						Offset: r.pos,
-						Data:   string(r.Data[r.pos:]),
-					}
-				}
-				return
+		case r.token.delimValue == '}' || r.token.delimValue == ']':
+			if stack[top] != (r.token.delimValue == '}') {
+				r.errSyntax()
+				break
 			}
-		case c == '\\' && inQuotes:
-			wasEscape = !wasEscape
+			stack = stack[:top]
+		case wantKey:
+			if r.token.kind != TokenString {
+				r.errSyntax()
+				break
+			}
+			r.validateToken()
+			r.consume()
+			r.WantColon()
+			wantKey = false
 			continue
-		case c == '"' && inQuotes:
-			inQuotes = wasEscape
-		case c == '"':
-			inQuotes = true
+		case r.token.delimValue == '{' || r.token.delimValue == '[':
+			stack = append(stack, r.token.delimValue == '{')
+			wantKey = r.token.delimValue == '{'
+			r.consume()
+			continue
+		default:
+			r.validateToken()
+		}
+		if !r.Ok() {
+			break
+		}
+
+		r.consume()
+		if len(stack) > 0 {
+			r.WantComma()
+			wantKey = stack[len(stack)-1]
 		}
-		wasEscape = false
 	}
-	r.pos = len(r.Data)
-	r.fatalError = &LexerError{
-		Reason: "EOF reached while skipping array/object or token",
-		Offset: r.pos,
-		Data:   string(r.Data[r.pos:]),
+
+	switch {
+	case r.fatalError == io.EOF:
+		r.fatalError = nil
+		r.AddError(&LexerError{
+			Reason: "EOF reached while skipping array/object or token",
+			Offset: r.dataOffset + r.pos,
+			Data:   string(r.Data[r.pos:]),
+		})
+	case r.fatalError != nil:
+		r.pos = len(r.Data)
+	}
+	if r.start = r.skipStart - r.dataOffset; r.start < 0 {
+		r.start = 0 // The beginning of the value was discarded.
+	}
+}
+
+// validateToken checks the current string or number token while skipping, unless it was
+// already checked when fetched in Strict mode.
+func (r *Lexer) validateToken() {
+	switch {
+	case r.Strict:
+	case r.token.kind == TokenString:
+		if i, reason := r.validateString(r.token.byteValue); reason != "" {
+			r.pos = r.start + 1 + i
+			r.errParse(reason)
+		}
+	case r.token.kind == TokenNumber:
+		if !isValidNumber(r.token.byteValue) {
+			r.pos = r.start
+			r.errParse("invalid number literal")
+		}
 	}
 }
 
 // Raw fetches the next item recursively as a data slice
 func (r *Lexer) Raw() []byte {
-	r.SkipRecursive()
+	r.skipRecursive(true)
 	if !r.Ok() {
 		return nil
 	}
@@ -593,31 +1182,69 @@ func (r *Lexer) Raw() []byte {
 }
 
 // IsStart returns whether the lexer is positioned at the start
-// of an input string.
+// of an input string, or of the next top-level value if MultipleValues is set.
 func (r *Lexer) IsStart() bool {
-	return r.pos == 0
+	return r.dataOffset+r.pos == r.valueStart
+}
+
+// IsEnd skips whitespace and returns whether the end of the input is reached.
+func (r *Lexer) IsEnd() bool {
+	if r.token.kind != TokenUndef || r.pos > len(r.Data) || !r.Ok() {
+		return false
+	}
+	return !r.skipWhitespace()
+}
+
+// skipWhitespace skips whitespace preceding the next token. Returns false if the end of
+// the input is reached.
+func (r *Lexer) skipWhitespace() (found bool) {
+	atStart := r.IsStart()
+	for !found {
+		for _, c := range r.Data[r.pos:] {
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				found = true
+				break
+			}
+
+			r.pos++
+			r.start++
+		}
+		if found && r.Lenient && r.Data[r.pos] == '/' {
+			r.skipComment()
+			found = !r.Ok()
+			continue
+		}
+		if !found && !r.fetchMore() {
+			break
+		}
+	}
+	if atStart {
+		// Leading whitespace is not a part of the value.
+		r.valueStart = r.dataOffset + r.pos
+	}
+	return found
 }
 
 // Consumed reads all remaining bytes from the input, publishing an error if
-// there is anything but whitespace remaining.
+// there is anything but whitespace remaining. If MultipleValues is set, it stops
+// at the beginning of the next top-level value instead.
 func (r *Lexer) Consumed() {
 	if r.pos > len(r.Data) || !r.Ok() {
 		return
 	}
 
-	for _, c := range r.Data[r.pos:] {
-		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
-			r.AddError(&LexerError{
-				Reason: "invalid character '" + string(c) + "' after top-level value",
-				Offset: r.pos,
-				Data:   string(r.Data[r.pos:]),
-			})
-			return
-		}
-
-		r.pos++
-		r.start++
+	if !r.skipWhitespace() {
+		return
+	}
+	if r.MultipleValues {
+		r.valueStart = r.dataOffset + r.pos
+		return
 	}
+	r.AddError(&LexerError{
+		Reason: "invalid character '" + string(r.Data[r.pos]) + "' after top-level value",
+		Offset: r.dataOffset + r.pos,
+		Data:   string(r.Data[r.pos:]),
+	})
 }
 
 func (r *Lexer) unsafeString(skipUnescape bool) (string, []byte) {
@@ -705,28 +1332,7 @@ func (r *Lexer) StringIntern() string {
 
 // Bytes reads a string literal and base64 decodes it into a byte slice.
 func (r *Lexer) Bytes() []byte {
-	if r.token.kind == TokenUndef && r.Ok() {
-		r.FetchToken()
-	}
-	if !r.Ok() || r.token.kind != TokenString {
-		r.errInvalidToken("string")
-		return nil
-	}
-	if err := r.unescapeStringToken(); err != nil {
-		r.errInvalidToken("string")
-		return nil
-	}
-	ret := make([]byte, base64.StdEncoding.DecodedLen(len(r.token.byteValue)))
-	n, err := base64.StdEncoding.Decode(ret, r.token.byteValue)
-	if err != nil {
-		r.fatalError = &LexerError{
-			Reason: err.Error(),
-		}
-		return nil
-	}
-
-	r.consume()
-	return ret[:n]
+	return r.decodeBytes(base64.StdEncoding)
 }
 
 // Bool reads a true or false boolean keyword.
@@ -765,7 +1371,7 @@ func (r *Lexer) Uint8() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -782,7 +1388,7 @@ func (r *Lexer) Uint16() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -799,7 +1405,7 @@ func (r *Lexer) Uint32() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -816,7 +1422,7 @@ func (r *Lexer) Uint64() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -837,7 +1443,7 @@ func (r *Lexer) Int8() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -854,7 +1460,7 @@ func (r *Lexer) Int16() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -871,7 +1477,7 @@ func (r *Lexer) Int32() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -888,7 +1494,7 @@ func (r *Lexer) Int64() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -909,7 +1515,7 @@ func (r *Lexer) Uint8Str() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -926,7 +1532,7 @@ func (r *Lexer) Uint16Str() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -943,7 +1549,7 @@ func (r *Lexer) Uint32Str() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -960,7 +1566,7 @@ func (r *Lexer) Uint64Str() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -985,7 +1591,7 @@ func (r *Lexer) Int8Str() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1002,7 +1608,7 @@ func (r *Lexer) Int16Str() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1019,7 +1625,7 @@ func (r *Lexer) Int32Str() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1036,7 +1642,7 @@ func (r *Lexer) Int64Str() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1057,7 +1663,7 @@ func (r *Lexer) Float32() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1073,7 +1679,7 @@ func (r *Lexer) Float32Str() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1090,7 +1696,7 @@ func (r *Lexer) Float64() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1106,7 +1712,7 @@ func (r *Lexer) Float64Str() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.dataOffset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1114,25 +1720,106 @@ func (r *Lexer) Float64Str() float64 {
 	return n
 }
 
+// RawNumber returns the literal of a number token without converting it. The slice refers to
+// the input data and must not be modified.
+func (r *Lexer) RawNumber() []byte {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() || r.token.kind != TokenNumber {
+		r.errInvalidToken("number")
+		return nil
+	}
+	ret := r.token.byteValue
+	r.consume()
+	return ret
+}
+
+// BigInt reads an integer number of arbitrary size.
+func (r *Lexer) BigInt() *big.Int {
+	n := new(big.Int)
+	s := r.number()
+	if !r.Ok() {
+		return n
+	}
+
+	if _, ok := n.SetString(s, 10); !ok {
+		r.addNonfatalError(&LexerError{
+			Offset: r.dataOffset + r.start,
+			Reason: "invalid big.Int number",
+			Data:   s,
+		})
+		n.SetInt64(0)
+	}
+	return n
+}
+
+// BigFloat reads a floating-point number with the precision large enough to keep all the
+// digits of the literal, but at least 64 bits.
+func (r *Lexer) BigFloat() *big.Float {
+	s := r.number()
+	if !r.Ok() {
+		return new(big.Float)
+	}
+
+	prec := uint(4 * len(s))
+	if prec < 64 {
+		prec = 64
+	}
+	n, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.dataOffset + r.start,
+			Reason: err.Error(),
+			Data:   s,
+		})
+		return new(big.Float)
+	}
+	return n
+}
+
+// BigRat reads a number as an exact rational.
+func (r *Lexer) BigRat() *big.Rat {
+	n := new(big.Rat)
+	s := r.number()
+	if !r.Ok() {
+		return n
+	}
+
+	if _, ok := n.SetString(s); !ok {
+		r.addNonfatalError(&LexerError{
+			Offset: r.dataOffset + r.start,
+			Reason: "invalid big.Rat number",
+			Data:   s,
+		})
+		n.SetInt64(0)
+	}
+	return n
+}
+
 func (r *Lexer) Error() error {
 	return r.fatalError
 }
 
 func (r *Lexer) AddError(e error) {
 	if r.fatalError == nil {
+		if err, ok := e.(*LexerError); ok {
+			r.locate(err)
+		}
 		r.fatalError = e
 	}
 }
 
 func (r *Lexer) AddNonFatalError(e error) {
 	r.addNonfatalError(&LexerError{
-		Offset: r.start,
+		Offset: r.dataOffset + r.start,
 		Data:   string(r.Data[r.start:r.pos]),
 		Reason: e.Error(),
 	})
 }
 
 func (r *Lexer) addNonfatalError(err *LexerError) {
+	r.locate(err)
 	if r.UseMultipleErrors {
 		// We don't want to add errors with the same offset.
 		if len(r.multipleErrors) != 0 && r.multipleErrors[len(r.multipleErrors)-1].Offset == err.Offset {
@@ -1186,7 +1873,7 @@ func (r *Lexer) Interface() interface{} {
 	case TokenString:
 		return r.String()
 	case TokenNumber:
-		return r.Float64()
+		return r.interfaceNumber()
 	case TokenBool:
 		return r.Bool()
 	case TokenNull:
@@ -1231,16 +1918,58 @@ func (r *Lexer) Interface() interface{} {
 	return nil
 }
 
+// interfaceNumber fetches a number as the type selected by UseNumber and UseInt64.
+func (r *Lexer) interfaceNumber() interface{} {
+	switch {
+	case r.UseNumber:
+		n := json.Number(string(r.token.byteValue))
+		r.consume()
+		return n
+	case r.UseInt64:
+		if n, err := strconv.ParseInt(bytesToStr(r.token.byteValue), 10, 64); err == nil {
+			r.consume()
+			return n
+		}
+	}
+	return r.Float64()
+}
+
 // WantComma requires a comma to be present before fetching next token.
 func (r *Lexer) WantComma() {
 	r.wantSep = ','
 	r.firstElement = false
+	if n := len(r.path); n > 0 && r.tracking() {
+		if r.path[n-1].isArray {
+			r.path[n-1].index++
+		} else {
+			r.path[n-1].key = nil
+		}
+	}
 }
 
 // WantColon requires a colon to be present before fetching next token.
 func (r *Lexer) WantColon() {
 	r.wantSep = ':'
 	r.firstElement = false
+	if len(r.path) > 0 {
+		r.trackMember()
+	}
+}
+
+// trackMember records the object member name just read in the tracked path.
+func (r *Lexer) trackMember() {
+	n := len(r.path)
+	if r.path[n-1].isArray || !r.tracking() {
+		return
+	}
+	r.path[n-1].key = r.token.byteValue
+	r.path[n-1].index++
+	if r.MaxObjectKeys > 0 && r.path[n-1].index > r.MaxObjectKeys {
+		r.errLimit(ReasonMaxObjectKeys)
+	}
+	if r.DisallowDuplicateKeys {
+		r.checkDuplicateKey(&r.path[n-1])
+	}
 }
 
 // CurrentToken returns current token kind if there were no errors and TokenUndef otherwise
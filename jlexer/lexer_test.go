@@ -3,8 +3,12 @@ package jlexer
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf8"
 )
 
 func TestString(t *testing.T) {
@@ -293,6 +297,41 @@ func TestConsumed(t *testing.T) {
 	}
 }
 
+func TestInterfaceNumbers(t *testing.T) {
+	data := `[5, -9007199254740993, 9223372036854775808, 1.5, 1e3]`
+	for i, test := range []struct {
+		lexer Lexer
+		want  []interface{}
+	}{
+		{
+			lexer: Lexer{},
+			want:  []interface{}{float64(5), float64(-9007199254740993), float64(9223372036854775808), 1.5, float64(1000)},
+		},
+		{
+			lexer: Lexer{UseNumber: true},
+			want:  []interface{}{json.Number("5"), json.Number("-9007199254740993"), json.Number("9223372036854775808"), json.Number("1.5"), json.Number("1e3")},
+		},
+		{
+			lexer: Lexer{UseInt64: true},
+			want:  []interface{}{int64(5), int64(-9007199254740993), float64(9223372036854775808), 1.5, float64(1000)},
+		},
+		{
+			lexer: Lexer{UseNumber: true, UseInt64: true},
+			want:  []interface{}{json.Number("5"), json.Number("-9007199254740993"), json.Number("9223372036854775808"), json.Number("1.5"), json.Number("1e3")},
+		},
+	} {
+		l := test.lexer
+		l.Data = []byte(data)
+
+		got := l.Interface()
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d] Interface() error: %v", i, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("[%d] Interface() = %#v; want %#v", i, got, test.want)
+		}
+	}
+}
+
 func TestJsonNumber(t *testing.T) {
 	for i, test := range []struct {
 		toParse        string
@@ -388,3 +427,631 @@ func TestCurrentToken(t *testing.T) {
 		l.Skip()
 	}
 }
+
+func TestReader(t *testing.T) {
+	for i, test := range []string{
+		`null`,
+		`12.5e-3`,
+		`"a long string with \"escapes\" and \\ slashes A"`,
+		`{"a": [1, 2, {"b": null}], "c": "d", "e": true, "f": false}`,
+		`  [ "x" , -15 , [ [ ] ] , { } ]  `,
+	} {
+		want := (&Lexer{Data: []byte(test)}).Interface()
+
+		l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(test))}
+		got := l.Interface()
+		l.Consumed()
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d, %q] Interface() error: %v", i, test, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d, %q] Interface() = %v; want %v", i, test, got, want)
+		}
+	}
+}
+
+func TestReaderRaw(t *testing.T) {
+	data := `{"skipped": {"a": [1, "]"]}, "raw": [1, {"b": "c"}]}`
+
+	l := Lexer{Reader: iotest.HalfReader(strings.NewReader(data))}
+	var raw []byte
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeFieldName(false)
+		l.WantColon()
+		switch key {
+		case "raw":
+			raw = l.Raw()
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+	l.Consumed()
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("Raw() error: %v", err)
+	}
+	if want := `[1, {"b": "c"}]`; string(raw) != want {
+		t.Errorf("Raw() = %s; want %s", raw, want)
+	}
+}
+
+func TestReaderSkipMemory(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString(`{"skipped": [`)
+	for i := 0; i < 100000; i++ {
+		data.WriteString(`{"a": "0123456789"}, `)
+	}
+	data.WriteString(`0], "b": 1}`)
+
+	l := Lexer{Reader: &data}
+	l.Delim('{')
+	l.UnsafeFieldName(false)
+	l.WantColon()
+	l.SkipRecursive()
+	if l.Error() != nil || len(l.Data) > 4*readerChunkSize {
+		t.Errorf("SkipRecursive() kept %d bytes, error %v", len(l.Data), l.Error())
+	}
+	l.WantComma()
+	if key := l.UnsafeFieldName(false); key != "b" {
+		t.Errorf("got key %q after SkipRecursive(); want %q", key, "b")
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(`[1, 2, nul]`))}
+	l.Interface()
+	if err, ok := l.Error().(*LexerError); !ok || err.Offset != 7 {
+		t.Errorf("Interface() error = %v; want syntax error at offset 7", l.Error())
+	}
+
+	l = Lexer{Reader: iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`[1, 2, 3]`)))}
+	l.Interface()
+	if l.Error() != iotest.ErrTimeout {
+		t.Errorf("Interface() error = %v; want %v", l.Error(), iotest.ErrTimeout)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		line    int
+		column  int
+		path    string
+	}{
+		{toParse: `nul`, line: 1, column: 1, path: "$"},
+		{toParse: "[1,\n 2,\n xyz]", line: 3, column: 2, path: "$[2]"},
+		{toParse: "{\"a\": {\"b\": [true, {\"c d\": \n  nope}]}}", line: 2, column: 3, path: `$.a.b[1]["c d"]`},
+		{toParse: `{"a": [1], "b": {"c": 1, "d": 1, } }`, line: 1, column: 34, path: "$.b"},
+	} {
+		for _, l := range []*Lexer{
+			{Data: []byte(test.toParse), TrackPath: true},
+			{Reader: iotest.OneByteReader(strings.NewReader(test.toParse)), TrackPath: true},
+		} {
+			l.Interface()
+
+			err, ok := l.Error().(*LexerError)
+			if !ok {
+				t.Errorf("[%d, %q] Interface() error = %v; want *LexerError", i, test.toParse, l.Error())
+				continue
+			}
+			if err.Line != test.line || err.Column != test.column {
+				t.Errorf("[%d, %q] Interface() error at %d:%d; want %d:%d", i, test.toParse, err.Line, err.Column, test.line, test.column)
+			}
+			if err.Path != test.path {
+				t.Errorf("[%d, %q] Interface() error path = %v; want %v", i, test.toParse, err.Path, test.path)
+			}
+		}
+	}
+}
+
+func TestPathSkipRecursive(t *testing.T) {
+	l := Lexer{Data: []byte(`[{"a": [1, {"b": 2}]}, "x"]`), TrackPath: true}
+
+	l.Delim('[')
+	l.SkipRecursive()
+	l.WantComma()
+	_ = l.String()
+	if got := l.Path(); got != "$[1]" {
+		t.Errorf("Path() = %v; want $[1]", got)
+	}
+	l.WantComma()
+	l.Delim(']')
+	if got := l.Path(); got != "$" {
+		t.Errorf("Path() = %v; want $", got)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	for i, test := range []string{
+		`null`, `nul`, `nullx`, `true`, `tru`, `false`, `falsey`,
+		`0`, `-0`, `01`, `-01`, `-`, `1.`, `.5`, `-.5`, `1.5`, `1e5`, `1E+5`, `1e-5`, `1e`, `1e+`, `1.e5`, `0.0e0`, `1.2.3`, `+1`,
+		`""`, `"abc"`, `"é\n\t\"\\\/"`, `"\x"`, `"\u12"`, `"\u12G4"`, "\"a\tb\"", "\"a\x00b\"", "\"\x7f\"", `"привет"`,
+		`[]`, `[1,2]`, `[1,]`, `[,1]`, `[1 2]`, `{}`, `{"a":1}`, `{"a":1,}`, `{"a"}`, `{"a" 1}`, `{1:2}`, `[1}`, `{"a":[1,{"b":null}]}`,
+		` [ 1 , "2" ] `, `1 2`, ``, `[`, `{"a":`,
+	} {
+		l := Lexer{Data: []byte(test), Strict: true}
+		l.Interface()
+		l.Consumed()
+
+		got := l.Error() == nil
+		if want := json.Valid([]byte(test)); got != want {
+			t.Errorf("[%d, %q] strict lexer valid = %v; json.Valid = %v (%v)", i, test, got, want, l.Error())
+		}
+	}
+
+	l := Lexer{Data: []byte("\"\xff\""), Strict: true}
+	_ = l.String()
+	if l.Error() == nil {
+		t.Errorf("String() ok; want error on invalid UTF-8")
+	}
+}
+
+func TestSkipRecursiveValidation(t *testing.T) {
+	for i, test := range []string{
+		`[]`, `{}`, `[1,2]`, `[1,]`, `[,1]`, `[1 2]`, `[1}`, `{"a"]`, `[[[]]`,
+		`{"a":1}`, `{"a":1,}`, `{"a"}`, `{"a" 1}`, `{1:2}`, `{"a":1 "b":2}`, `{"a"::1}`, `{"a":1,,"b":2}`,
+		`[01]`, `[-]`, `[1.]`, `[.5]`, `[1e]`, `[1.5e+10]`, `[-0.0]`, `[1.2.3]`, `[nul]`, `[true,false,null]`, `[truex]`,
+		`[""]`, `["\x"]`, `["\u12G4"]`, "[\"a\tb\"]", "[\"\xff\"]", `["\"]"]`, `{"\u0041":"\n\/"}`,
+		`{"a":[1,{"b":[null,{"c":{}}]}],"d":"]}"}`, `[1,[2,[3,[4]]],{"e":[5]}]`,
+	} {
+		for _, l := range []*Lexer{
+			{Data: []byte(test + " ,")},
+			{Reader: iotest.OneByteReader(strings.NewReader(test + " ,"))},
+		} {
+			raw := l.Raw()
+
+			got := l.Error() == nil
+			if want := json.Valid([]byte(test)); got != want {
+				t.Errorf("[%d, %q] SkipRecursive() valid = %v; json.Valid = %v (%v)", i, test, got, want, l.Error())
+			} else if got && string(raw) != test {
+				t.Errorf("[%d, %q] Raw() = %q", i, test, raw)
+			}
+		}
+	}
+}
+
+func TestValidateStringWords(t *testing.T) {
+	// Put each character at every position of a string longer than a word.
+	for _, special := range []string{`\"`, `\\`, `\u0041`, `\x`, "\x01", "\x1f", "\x7f", "é", "\xff"} {
+		for i := 0; i <= 17; i++ {
+			test := `["` + strings.Repeat("a", i) + special + strings.Repeat("b", 17-i) + `"]`
+			want := json.Valid([]byte(test))
+
+			l := Lexer{Data: []byte(test)}
+			l.SkipRecursive()
+			if got := l.Error() == nil; got != want {
+				t.Errorf("[%q] SkipRecursive() valid = %v; json.Valid = %v", test, got, want)
+			}
+
+			l = Lexer{Data: []byte(test), Strict: true}
+			l.SkipRecursive()
+			if got := l.Error() == nil; got != (want && utf8.ValidString(test)) {
+				t.Errorf("[%q] strict SkipRecursive() valid = %v; want %v", test, got, !got)
+			}
+		}
+	}
+}
+
+func TestMultipleValues(t *testing.T) {
+	l := Lexer{Data: []byte(" 1\n\"a\" [2]  \n"), MultipleValues: true}
+
+	var got []interface{}
+	for !l.IsEnd() {
+		if !l.IsStart() {
+			t.Fatalf("IsStart() = false at value %d", len(got))
+		}
+		got = append(got, l.Interface())
+		l.Consumed()
+	}
+	if err := l.Error(); err != nil {
+		t.Fatalf("Interface() error: %v", err)
+	}
+
+	want := []interface{}{float64(1), "a", []interface{}{float64(2)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Interface() = %v; want %v", got, want)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      interface{}
+		wantError bool
+	}{
+		{toParse: `// comment
+			{
+				/* block
+				   comment */
+				unquoted: 'single "quoted"', // trailing comment
+				"array": [1, 2, /* inner */ 3,],
+				'it\'s': true,
+				null: null,
+				_id$2: false,
+			}
+			// comment at the end`,
+			want: map[string]interface{}{
+				"unquoted": `single "quoted"`,
+				"array":    []interface{}{float64(1), float64(2), float64(3)},
+				"it's":     true,
+				"null":     nil,
+				"_id$2":    false,
+			},
+		},
+		{toParse: `[1//c
+			]`, want: []interface{}{float64(1)}},
+		{toParse: `{a:{b:[]},}`, want: map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{}}}},
+		{toParse: `{a /* c */ : 1, b // c
+			: [true /* c */, null // c
+			]}`, want: map[string]interface{}{"a": float64(1), "b": []interface{}{true, nil}}},
+		{toParse: `{a /* unterminated : 1}`, wantError: true},
+
+		{toParse: `[1,,]`, wantError: true},
+		{toParse: `[,]`, wantError: true},
+		{toParse: `{a b: 1}`, wantError: true},
+		{toParse: `[undefined]`, wantError: true},
+		{toParse: `[1 /* unterminated`, wantError: true},
+		{toParse: `[1 / 2]`, wantError: true},
+	} {
+		for _, l := range []*Lexer{
+			{Data: []byte(test.toParse), Lenient: true},
+			{Reader: iotest.OneByteReader(strings.NewReader(test.toParse)), Lenient: true},
+		} {
+			got := l.Interface()
+			l.Consumed()
+
+			err := l.Error()
+			if err != nil && !test.wantError {
+				t.Errorf("[%d, %q] Interface() error: %v", i, test.toParse, err)
+			} else if err == nil && test.wantError {
+				t.Errorf("[%d, %q] Interface() ok; want error", i, test.toParse)
+			} else if !test.wantError && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("[%d, %q] Interface() = %v; want %v", i, test.toParse, got, test.want)
+			}
+		}
+	}
+}
+
+func TestLenientSkipRecursive(t *testing.T) {
+	data := `{a: ['}', "]", /* } */ 1,], // }
+		b: {}}, 4`
+
+	l := Lexer{Data: []byte(data), Lenient: true}
+	raw := l.Raw()
+	if err := l.Error(); err != nil {
+		t.Fatalf("Raw() error: %v", err)
+	}
+	if want := data[:len(data)-3]; string(raw) != want {
+		t.Errorf("Raw() = %q; want %q", raw, want)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		lexer     Lexer
+		skip      bool
+		wantError string
+	}{
+		{toParse: `[[1, [2]], {"a": [3]}]`, lexer: Lexer{MaxDepth: 3}},
+		{toParse: `[[1, [[2]]]]`, lexer: Lexer{MaxDepth: 3}, wantError: ReasonMaxDepth},
+		{toParse: `[[1, [[2]]]]`, lexer: Lexer{MaxDepth: 3}, skip: true, wantError: ReasonMaxDepth},
+		{toParse: `{"a": {"b": {"c": {}}}}`, lexer: Lexer{MaxDepth: 3}, wantError: ReasonMaxDepth},
+		{toParse: `{"a": {"b": {"c": {}}}}`, lexer: Lexer{MaxDepth: 4}, skip: true},
+
+		{toParse: `["abc", "d\"e"]`, lexer: Lexer{MaxStringLen: 4}},
+		{toParse: `["abc", "abcde"]`, lexer: Lexer{MaxStringLen: 4}, wantError: ReasonMaxStringLen},
+		{toParse: `{"abcde": 1}`, lexer: Lexer{MaxStringLen: 4}, wantError: ReasonMaxStringLen},
+
+		{toParse: `[[1, 2], [3, 4]]`, lexer: Lexer{MaxArrayLen: 2}},
+		{toParse: `[[1, 2, 3]]`, lexer: Lexer{MaxArrayLen: 2}, wantError: ReasonMaxArrayLen},
+		{toParse: `[[1, 2], [3, 4], []]`, lexer: Lexer{MaxArrayLen: 2}, wantError: ReasonMaxArrayLen},
+
+		{toParse: `{"a": {"b": 1, "c": 2}, "d": {}}`, lexer: Lexer{MaxObjectKeys: 2}},
+		{toParse: `{"a": 1, "b": 2, "c": 3}`, lexer: Lexer{MaxObjectKeys: 2}, wantError: ReasonMaxObjectKeys},
+	} {
+		for _, reader := range []bool{false, true} {
+			l := test.lexer
+			if reader {
+				l.Reader = iotest.OneByteReader(strings.NewReader(test.toParse))
+			} else {
+				l.Data = []byte(test.toParse)
+			}
+			if test.skip {
+				l.SkipRecursive()
+			} else {
+				l.Interface()
+			}
+
+			err := l.Error()
+			if test.wantError == "" {
+				if err != nil {
+					t.Errorf("[%d, %q] error: %v", i, test.toParse, err)
+				}
+				continue
+			}
+			if lerr, ok := err.(*LexerError); !ok || lerr.Reason != test.wantError {
+				t.Errorf("[%d, %q] error: %v; want %q", i, test.toParse, err, test.wantError)
+			}
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	type tok struct {
+		Kind   TokenKind
+		Key    bool
+		Value  string
+		Raw    string
+		Offset int
+		Depth  int
+	}
+	for i, test := range []struct {
+		toParse        string
+		multipleValues bool
+		want           []tok
+		wantError      error
+	}{
+		{
+			toParse: ` {"a\n": [1.5, "xA"], "b": {}, "c": [true, null]} `,
+			want: []tok{
+				{TokenDelim, false, "{", "{", 1, 0},
+				{TokenString, true, "a\n", `"a\n"`, 2, 1},
+				{TokenDelim, false, "[", "[", 9, 1},
+				{TokenNumber, false, "1.5", "1.5", 10, 2},
+				{TokenString, false, "xA", `"xA"`, 15, 2},
+				{TokenDelim, false, "]", "]", 19, 1},
+				{TokenString, true, "b", `"b"`, 22, 1},
+				{TokenDelim, false, "{", "{", 27, 1},
+				{TokenDelim, false, "}", "}", 28, 1},
+				{TokenString, true, "c", `"c"`, 31, 1},
+				{TokenDelim, false, "[", "[", 36, 1},
+				{TokenBool, false, "true", "true", 37, 2},
+				{TokenNull, false, "null", "null", 43, 2},
+				{TokenDelim, false, "]", "]", 47, 1},
+				{TokenDelim, false, "}", "}", 48, 0},
+			},
+			wantError: io.EOF,
+		},
+		{
+			toParse:        `1 "a" []`,
+			multipleValues: true,
+			want: []tok{
+				{TokenNumber, false, "1", "1", 0, 0},
+				{TokenString, false, "a", `"a"`, 2, 0},
+				{TokenDelim, false, "[", "[", 6, 0},
+				{TokenDelim, false, "]", "]", 7, 0},
+			},
+			wantError: io.EOF,
+		},
+		{
+			toParse:   `[1, {"a": 2`,
+			want:      []tok{{TokenDelim, false, "[", "[", 0, 0}, {TokenNumber, false, "1", "1", 1, 1}, {TokenDelim, false, "{", "{", 4, 1}, {TokenString, true, "a", `"a"`, 5, 2}, {TokenNumber, false, "2", "2", 10, 2}},
+			wantError: io.ErrUnexpectedEOF,
+		},
+		{toParse: `1 2`, want: []tok{{TokenNumber, false, "1", "1", 0, 0}}},
+		{toParse: `[}`, want: []tok{{TokenDelim, false, "[", "[", 0, 0}}},
+		{toParse: `{1: 2}`, want: []tok{{TokenDelim, false, "{", "{", 0, 0}}},
+		{toParse: `{"a" 2}`, want: []tok{{TokenDelim, false, "{", "{", 0, 0}, {TokenString, true, "a", `"a"`, 1, 1}}},
+		{toParse: `[1 2]`, want: []tok{{TokenDelim, false, "[", "[", 0, 0}, {TokenNumber, false, "1", "1", 1, 1}}},
+		{toParse: `[1,]`, want: []tok{{TokenDelim, false, "[", "[", 0, 0}, {TokenNumber, false, "1", "1", 1, 1}}},
+	} {
+		for _, l := range []*Lexer{
+			{Data: []byte(test.toParse), MultipleValues: test.multipleValues},
+			{Reader: iotest.OneByteReader(strings.NewReader(test.toParse)), MultipleValues: test.multipleValues},
+		} {
+			var got []tok
+			var err error
+			for {
+				var token Token
+				if token, err = l.Next(); err != nil {
+					break
+				}
+				got = append(got, tok{token.Kind, token.Key, string(token.Value), string(token.Raw), token.Offset, token.Depth})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("[%d, %q] Next() = %v; want %v", i, test.toParse, got, test.want)
+			}
+			if test.wantError != nil && err != test.wantError {
+				t.Errorf("[%d, %q] Next() error: %v; want %v", i, test.toParse, err, test.wantError)
+			} else if _, ok := err.(*LexerError); test.wantError == nil && !ok {
+				t.Errorf("[%d, %q] Next() error: %v; want syntax error", i, test.toParse, err)
+			}
+		}
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		lenient bool
+		offsets []int
+	}{
+		{toParse: `{"a": 1, "b": {"a": 2}, "c": [{"a": 3}, {"a": 4}]}`},
+		{toParse: `{"a": 1, "b": 2, "a": 3}`, offsets: []int{17}},
+		{toParse: `{"a": {"b": 1, "b": 2}, "a": {"b": 3}}`, offsets: []int{15, 24}},
+		{toParse: `{"a\u0062": 1, "a\\u0062": 2, "ab": 3}`, offsets: []int{30}},
+		{toParse: `[{"a": 1}, {"a": 2, "a": 3}]`, offsets: []int{20}},
+		{toParse: `{a: 1, 'a': 2}`, lenient: true, offsets: []int{7}},
+	} {
+		l := Lexer{Data: []byte(test.toParse), Lenient: test.lenient, DisallowDuplicateKeys: true, UseMultipleErrors: true}
+		l.Interface()
+
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d, %q] Interface() error: %v", i, test.toParse, err)
+			continue
+		}
+		var offsets []int
+		for _, err := range l.GetNonFatalErrors() {
+			if err.Reason != ReasonDuplicateKey {
+				t.Errorf("[%d, %q] Interface() error: %v; want %q", i, test.toParse, err, ReasonDuplicateKey)
+			}
+			offsets = append(offsets, err.Offset)
+		}
+		if !reflect.DeepEqual(offsets, test.offsets) {
+			t.Errorf("[%d, %q] Interface() error offsets = %v; want %v", i, test.toParse, offsets, test.offsets)
+		}
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      string
+		read      func(l *Lexer) string
+		wantError bool
+	}{
+		{toParse: `-1.25e+3`, want: "-1.25e+3", read: func(l *Lexer) string { return string(l.RawNumber()) }},
+		{toParse: `"1"`, wantError: true, read: func(l *Lexer) string { return string(l.RawNumber()) }},
+		{toParse: `-123456789012345678901234567890`, want: "-123456789012345678901234567890", read: func(l *Lexer) string { return l.BigInt().String() }},
+		{toParse: `1e3`, want: "0", wantError: true, read: func(l *Lexer) string { return l.BigInt().String() }},
+		{toParse: `1.00000000000000000000000000001`, want: "1.00000000000000000000000000001", read: func(l *Lexer) string { return l.BigFloat().Text('g', -1) }},
+		{toParse: `0.1`, want: "0.1", read: func(l *Lexer) string { return l.BigFloat().Text('g', -1) }},
+		{toParse: `1e400`, want: "1e+400", read: func(l *Lexer) string { return l.BigFloat().Text('g', -1) }},
+		{toParse: `-0.125`, want: "-1/8", read: func(l *Lexer) string { return l.BigRat().String() }},
+		{toParse: `true`, want: "0/1", wantError: true, read: func(l *Lexer) string { return l.BigRat().String() }},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+		got := test.read(&l)
+		if got != test.want {
+			t.Errorf("[%d, %q] got %q; want %q", i, test.toParse, got, test.want)
+		}
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] ok; want error", i, test.toParse)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	l := Lexer{Data: []byte(`{"a": [1, "b"]}`), UseMultipleErrors: true, TrackPath: true, Strict: true}
+	l.Delim('{')
+	_ = l.String()
+	l.WantColon()
+	l.Delim('[')
+	l.Int()
+	l.WantComma()
+	l.Int()
+	if len(l.GetNonFatalErrors()) != 1 {
+		t.Fatalf("GetNonFatalErrors() = %v; want 1 error", l.GetNonFatalErrors())
+	}
+
+	l.Reset([]byte(` [1, 2] `))
+	if !l.UseMultipleErrors || !l.TrackPath || !l.Strict {
+		t.Errorf("Reset() did not keep the options")
+	}
+	if len(l.GetNonFatalErrors()) != 0 || l.Error() != nil || l.Path() != "$" {
+		t.Errorf("Reset() did not clear the state: %v, %v, %s", l.GetNonFatalErrors(), l.Error(), l.Path())
+	}
+	if !l.IsStart() {
+		t.Errorf("IsStart() = false after Reset()")
+	}
+
+	got := l.Interface()
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		t.Fatalf("Interface() error: %v", err)
+	}
+	if want := []interface{}{float64(1), float64(2)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Interface() = %v; want %v", got, want)
+	}
+}
+
+func TestResetAll(t *testing.T) {
+	l := Lexer{Data: []byte(`{"a": 1}`), UseMultipleErrors: true, TrackPath: true, MaxDepth: 1}
+	l.Interface()
+
+	l.ResetAll([]byte(`[[1]]`))
+	if l.UseMultipleErrors || l.TrackPath || l.MaxDepth != 0 {
+		t.Errorf("ResetAll() did not clear the options")
+	}
+	got := l.Interface()
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		t.Fatalf("Interface() error: %v", err)
+	}
+	if want := []interface{}{[]interface{}{float64(1)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Interface() = %v; want %v", got, want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	data := []byte(`{"meta": {"id": "a\"b", "tags": [1, {"x": null}, 3]}, "meta": 1, "n": 5} garbage`)
+	for i, test := range []struct {
+		path []string
+		want string
+	}{
+		{path: nil, want: `{"meta": {"id": "a\"b", "tags": [1, {"x": null}, 3]}, "meta": 1, "n": 5}`},
+		{path: []string{"meta", "id"}, want: `"a\"b"`},
+		{path: []string{"meta", "tags"}, want: `[1, {"x": null}, 3]`},
+		{path: []string{"meta", "tags", "1", "x"}, want: `null`},
+		{path: []string{"meta", "tags", "2"}, want: `3`},
+		{path: []string{"meta", "tags", "3"}},
+		{path: []string{"meta", "tags", "-1"}},
+		{path: []string{"meta", "tags", "x"}},
+		{path: []string{"meta", "id", "x"}},
+		{path: []string{"missing"}},
+		{path: []string{"n", "x"}},
+	} {
+		got, err := Get(data, test.path...)
+		if err != nil {
+			t.Errorf("[%d] Get(%q) error: %v", i, test.path, err)
+		}
+		if string(got) != test.want || (got == nil) != (test.want == "") {
+			t.Errorf("[%d] Get(%q) = %q; want %q", i, test.path, got, test.want)
+		}
+	}
+
+	l := Lexer{Data: data}
+	if !l.Find("meta", "id") {
+		t.Fatalf("Find() = false")
+	}
+	if got := l.String(); got != `a"b` || l.Error() != nil {
+		t.Errorf("Find() + String() = %q, %v", got, l.Error())
+	}
+
+	if _, err := Get([]byte(`{"a": [}`), "b"); err == nil {
+		t.Errorf("Get() expected error for invalid input")
+	}
+	if _, err := Get([]byte(`{"a":1,"b":`), "b"); !isLexerError(err) {
+		t.Errorf("Get() error %#v for truncated input; want *LexerError", err)
+	}
+}
+
+func isLexerError(err error) bool {
+	_, ok := err.(*LexerError)
+	return ok
+}
+
+func TestGetMany(t *testing.T) {
+	data := []byte(`{"a": {"b": [1, 2], "c": "x"}, "d": true, "a": 0, "e": {}}`)
+	got, err := GetMany(data, []string{"a", "c"}, []string{"a"}, []string{"a", "b", "1"}, []string{"e", "f"}, []string{"d"})
+	if err != nil {
+		t.Fatalf("GetMany() error: %v", err)
+	}
+	want := []string{`"x"`, `{"b": [1, 2], "c": "x"}`, `2`, "", `true`}
+	for i := range want {
+		if string(got[i]) != want[i] || (got[i] == nil) != (want[i] == "") {
+			t.Errorf("GetMany()[%d] = %q; want %q", i, got[i], want[i])
+		}
+	}
+
+	// Reading stops once all the values are found, the rest is not validated.
+	got, err = GetMany([]byte(`{"a": 1, "b": 2, "c": [}`), []string{"b"}, []string{"a"})
+	if err != nil || string(got[0]) != "2" || string(got[1]) != "1" {
+		t.Errorf("GetMany() = %q, %v; want [2 1]", got, err)
+	}
+	if _, err = GetMany([]byte(`{"a": 1, "b": [}`), []string{"c"}); err == nil {
+		t.Errorf("GetMany() expected error for invalid input")
+	}
+	for _, data := range []string{`{"a":1,"b":`, `{"a":1,"b"`, `{"a":1`, ``} {
+		if got, err := GetMany([]byte(data), []string{"a"}, []string{"b"}); !isLexerError(err) {
+			t.Errorf("GetMany(%q) = %q, %#v; want *LexerError", data, got, err)
+		}
+	}
+}
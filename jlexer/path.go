@@ -0,0 +1,166 @@
+package jlexer
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// pathElem describes a single array or object on the path to the value being parsed.
+type pathElem struct {
+	offset  int    // Offset of the opening delimiter in the input stream.
+	isArray bool   // Whether the element is an array rather than an object.
+	index   int    // Index of the current array item, or number of object members read.
+	key     []byte // Name of the current object member, nil if not read yet.
+
+	keys map[string]struct{} // Member names read so far if DisallowDuplicateKeys is set.
+}
+
+// tracking returns whether the arrays and objects enclosing the current token have to be
+// tracked, either to report the path or to enforce the limits.
+func (r *Lexer) tracking() bool {
+	return r.TrackPath || r.DisallowDuplicateKeys || r.MaxDepth > 0 || r.MaxArrayLen > 0 || r.MaxObjectKeys > 0
+}
+
+// beginValue checks that a value can start at the current token. It is kept small enough to be
+// inlined, as it is called for every value.
+func (r *Lexer) beginValue() {
+	if r.wantSep != 0 || r.MaxArrayLen > 0 {
+		r.checkValueStart()
+	}
+}
+
+// checkValueStart reports an error if a value cannot start at the current token: a separator is
+// missing or the value exceeds MaxArrayLen.
+func (r *Lexer) checkValueStart() {
+	if r.wantSep != 0 {
+		r.errSyntax()
+	}
+	if n := len(r.path); r.MaxArrayLen > 0 && n > 0 && r.path[n-1].isArray && r.path[n-1].index >= r.MaxArrayLen {
+		r.errLimit(ReasonMaxArrayLen)
+	}
+}
+
+// pushPath starts tracking an array or object starting at the current token.
+func (r *Lexer) pushPath(isArray bool) {
+	offset := r.dataOffset + r.start
+	if n := len(r.path); n > 0 && r.path[n-1].offset == offset {
+		// The token is fetched again after being rewound, it is already tracked.
+		return
+	}
+	if r.MaxDepth > 0 && len(r.path) >= r.MaxDepth {
+		r.errLimit(ReasonMaxDepth)
+		return
+	}
+	if n := len(r.path); n < cap(r.path) {
+		// Reuse the set of member names of a previously tracked object.
+		keys := r.path[:n+1][n].keys
+		for k := range keys {
+			delete(keys, k)
+		}
+		r.path = append(r.path, pathElem{offset: offset, isArray: isArray, keys: keys})
+		return
+	}
+	r.path = append(r.path, pathElem{offset: offset, isArray: isArray})
+}
+
+// checkDuplicateKey reports an error if the object member name just read has already occurred
+// in the object.
+func (r *Lexer) checkDuplicateKey(e *pathElem) {
+	// The name token still spans r.Data[r.start:r.pos], unescape it again as it may have been
+	// read without unescaping.
+	name := r.Data[r.start:r.pos]
+	if n := len(name); n >= 2 && (name[0] == '"' || name[0] == '\'') {
+		name = name[1 : n-1]
+		if unescaped, err := r.unescape(name); err != nil {
+			return
+		} else if unescaped != nil {
+			name = unescaped
+		}
+	}
+
+	if e.keys == nil {
+		e.keys = make(map[string]struct{})
+	}
+	if _, ok := e.keys[string(name)]; !ok {
+		e.keys[string(name)] = struct{}{}
+		return
+	}
+	r.addNonfatalError(&LexerError{
+		Offset: r.dataOffset + r.start,
+		Reason: ReasonDuplicateKey,
+		Data:   string(name),
+	})
+}
+
+// popPath stops tracking the innermost array or object.
+func (r *Lexer) popPath() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// Path returns the JSON path of the value being parsed, e.g. $.orders[12].items[3].price.
+// The path is only tracked if TrackPath is set, otherwise "$" is returned.
+func (r *Lexer) Path() string {
+	buf := []byte{'$'}
+	for _, e := range r.path {
+		switch {
+		case e.isArray:
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(e.index), 10)
+			buf = append(buf, ']')
+		case e.key == nil:
+		case isIdentifier(e.key):
+			buf = append(buf, '.')
+			buf = append(buf, e.key...)
+		default:
+			buf = append(buf, '[')
+			buf = strconv.AppendQuote(buf, string(e.key))
+			buf = append(buf, ']')
+		}
+	}
+	return string(buf)
+}
+
+// isIdentifier returns true if the member name can be used in a path without quoting.
+func isIdentifier(name []byte) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// position returns the line and column of the given offset in the input stream. The offset
+// is expected to point into the current Data window.
+func (r *Lexer) position(offset int) (line, column int) {
+	pos := offset - r.dataOffset
+	if pos < 0 {
+		pos = 0
+	} else if pos > len(r.Data) {
+		pos = len(r.Data)
+	}
+
+	data := r.Data[:pos]
+	line = r.linesDiscarded + bytes.Count(data, []byte{'\n'}) + 1
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		return line, pos - i
+	}
+	return line, r.dataOffset + pos - r.lineStart + 1
+}
+
+// locate fills in the line, column and path of the error.
+func (r *Lexer) locate(err *LexerError) {
+	if err.Line != 0 {
+		return
+	}
+	err.Line, err.Column = r.position(err.Offset)
+	if r.TrackPath {
+		err.Path = r.Path()
+	}
+}
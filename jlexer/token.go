@@ -0,0 +1,97 @@
+package jlexer
+
+import "io"
+
+// Token is a single token of the input as returned by Next.
+type Token struct {
+	Kind TokenKind // Type of the token, one of TokenDelim, TokenString, TokenNumber, TokenBool or TokenNull.
+	Key  bool      // Whether the token is an object member name.
+
+	// Value is the unescaped value of a string, and the literal itself for the other kinds.
+	// Both Value and Raw may refer to the input data and must not be modified.
+	Value []byte
+	Raw   []byte // The token as it appears in the input.
+
+	Offset int // Offset of the token in the input stream.
+	Depth  int // Number of arrays and objects enclosing the token.
+}
+
+// Delim returns the delimiter character if the token is a delimiter and 0 otherwise.
+func (t Token) Delim() byte {
+	if t.Kind != TokenDelim {
+		return 0
+	}
+	return t.Raw[0]
+}
+
+// Next returns the next token of the input, checking that the tokens form valid JSON. Commas
+// and colons are consumed implicitly. At the end of the input, or of the first top-level value
+// unless MultipleValues is set, it returns io.EOF; if the input ends in the middle of a value
+// it returns io.ErrUnexpectedEOF.
+//
+// Next keeps its own state and should not be mixed with the other reading methods.
+func (r *Lexer) Next() (Token, error) {
+	depth := len(r.nextObjects)
+	if depth == 0 && r.nextDone && !r.MultipleValues {
+		r.Consumed()
+		if r.Ok() {
+			r.fatalError = io.EOF
+		}
+	}
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		if r.fatalError == io.EOF && (depth > 0 || r.wantSep != 0) {
+			return Token{}, io.ErrUnexpectedEOF
+		}
+		return Token{}, r.fatalError
+	}
+
+	closing := r.token.delimValue == '}' || r.token.delimValue == ']'
+	tok := Token{
+		Kind:   r.token.kind,
+		Key:    depth > 0 && r.nextObjects[depth-1] && r.nextKey && !closing,
+		Raw:    r.Data[r.start:r.pos],
+		Offset: r.dataOffset + r.start,
+		Depth:  depth,
+	}
+	tok.Value = tok.Raw
+
+	switch {
+	case tok.Key && tok.Kind != TokenString:
+		r.errSyntax()
+	case tok.Kind == TokenString:
+		if err := r.unescapeStringToken(); err != nil {
+			r.errInvalidToken("string")
+			break
+		}
+		tok.Value = r.token.byteValue
+	case tok.Kind != TokenDelim:
+	case !closing:
+		r.nextObjects = append(r.nextObjects, r.token.delimValue == '{')
+		r.nextKey = true
+	case depth == 0 || r.nextObjects[depth-1] != (r.token.delimValue == '}'):
+		r.errSyntax()
+	default:
+		r.nextObjects = r.nextObjects[:depth-1]
+		tok.Depth--
+	}
+	if !r.Ok() {
+		return Token{}, r.fatalError
+	}
+	r.consume()
+
+	switch {
+	case tok.Key:
+		r.WantColon()
+		r.nextKey = false
+	case tok.Delim() == '{' || tok.Delim() == '[':
+	case len(r.nextObjects) == 0:
+		r.nextDone = true
+	default:
+		r.WantComma()
+		r.nextKey = true
+	}
+	return tok, nil
+}
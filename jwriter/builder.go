@@ -0,0 +1,122 @@
+package jwriter
+
+import "errors"
+
+// builderScope is an array or object opened by BeginArray or BeginObject.
+type builderScope struct {
+	object bool // Whether the scope is an object rather than an array.
+	n      int  // Number of the members or items started so far.
+	offset int  // Buffer offset after the opening delimiter or the last Key or Item.
+}
+
+// BeginObject starts an object; each of its members is written with Key followed by exactly one
+// value, and the object is finished with EndObject. Separators are written automatically, e.g.
+//
+//	w.BeginObject()
+//	w.Key("id")
+//	w.Int(v.ID)
+//	w.Key("tags")
+//	w.BeginArray()
+//	for _, tag := range v.Tags {
+//		w.Item()
+//		w.String(tag)
+//	}
+//	w.EndArray()
+//	w.EndObject()
+//
+// Calls that do not match the structure set Error: Key outside of an object, Key or EndObject
+// with no value written after the previous Key, or a value written before the first Key, and the
+// same for arrays and Item. Arrays and objects that are not finished set Error once the output is
+// built with BuildBytes, DumpTo, ReadCloser or Flush. Several values written after a single Key
+// or Item are not detected.
+func (w *Writer) BeginObject() {
+	w.RawOpen('{')
+	w.scopes = append(w.scopes, builderScope{object: true, offset: w.Buffer.Offset()})
+}
+
+// Key starts an object member with the given name, the value must be written next.
+func (w *Writer) Key(name string) {
+	s := w.scope(true, "Key")
+	if s == nil {
+		return
+	}
+	if s.n > 0 {
+		w.Buffer.AppendByte(',')
+	}
+	s.n++
+	w.RawIndent()
+	w.String(name)
+	w.RawColon()
+	s.offset = w.Buffer.Offset()
+}
+
+// EndObject finishes the object started by BeginObject.
+func (w *Writer) EndObject() {
+	if w.scope(true, "EndObject") == nil {
+		return
+	}
+	w.scopes = w.scopes[:len(w.scopes)-1]
+	w.RawClose('}')
+}
+
+// BeginArray starts an array; each of its items is written with Item followed by exactly one
+// value, and the array is finished with EndArray.
+func (w *Writer) BeginArray() {
+	w.RawOpen('[')
+	w.scopes = append(w.scopes, builderScope{offset: w.Buffer.Offset()})
+}
+
+// Item starts an array item, the value must be written next.
+func (w *Writer) Item() {
+	s := w.scope(false, "Item")
+	if s == nil {
+		return
+	}
+	if s.n > 0 {
+		w.Buffer.AppendByte(',')
+	}
+	s.n++
+	w.RawIndent()
+	s.offset = w.Buffer.Offset()
+}
+
+// EndArray finishes the array started by BeginArray.
+func (w *Writer) EndArray() {
+	if w.scope(false, "EndArray") == nil {
+		return
+	}
+	w.scopes = w.scopes[:len(w.scopes)-1]
+	w.RawClose(']')
+}
+
+// scope returns the innermost scope if it is an object or an array as requested, otherwise it
+// sets Error for the method call and returns nil. It also sets Error if no value was written
+// after the previous Key or Item, or if a value was written before the first one.
+func (w *Writer) scope(object bool, method string) *builderScope {
+	kind, start := "an array", "Item"
+	if object {
+		kind, start = "an object", "Key"
+	}
+
+	n := len(w.scopes)
+	if n == 0 || w.scopes[n-1].object != object {
+		w.builderError(method + " called outside of " + kind)
+		return nil
+	}
+
+	s := &w.scopes[n-1]
+	switch written := w.Buffer.Offset() != s.offset; {
+	case s.n == 0 && written:
+		w.builderError("value written in " + kind + " without " + start)
+	case s.n > 0 && !written:
+		w.builderError(method + " called with no value after " + start)
+	}
+	return s
+}
+
+// builderError sets Error for a call that does not match the structure.
+func (w *Writer) builderError(msg string) {
+	if w.Error == nil {
+		w.Error = errors.New("jwriter: " + msg)
+	}
+}
@@ -0,0 +1,148 @@
+package jwriter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+// maxSafeInteger is the largest integer that is exactly representable as a float64, larger
+// integers are written as the nearest float64 in the canonical mode.
+const maxSafeInteger = 1 << 53
+
+// canonicalField writes a struct field prefix of the form `,"name":` in the canonical mode.
+func (w *Writer) canonicalField(prefix string) {
+	if prefix[0] == ',' {
+		w.Buffer.AppendByte(',')
+		prefix = prefix[1:]
+	}
+	w.RawIndent()
+	name, err := strconv.Unquote(prefix[:len(prefix)-1])
+	if err != nil {
+		name = prefix[1 : len(prefix)-2]
+	}
+	w.canonicalString(name)
+	w.Buffer.AppendByte(':')
+}
+
+// canonicalString writes a string with the escaping of RFC 8785: only the quote, the backslash
+// and the control characters are escaped, the latter with the short forms where available.
+func (w *Writer) canonicalString(s string) {
+	w.Buffer.AppendByte('"')
+	p := 0
+	for i := 0; i < len(s); {
+		if i = skipNoEscape(s, i, false); i >= len(s) {
+			break
+		}
+		c := s[i]
+
+		if c >= utf8.RuneSelf {
+			runeValue, runeWidth := utf8.DecodeRuneInString(s[i:])
+			if runeValue == utf8.RuneError && runeWidth == 1 {
+				w.Buffer.AppendString(s[p:i])
+				w.Buffer.AppendString("\ufffd")
+				p = i + 1
+			}
+			i += runeWidth
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+
+		w.Buffer.AppendString(s[p:i])
+		switch c {
+		case '\b':
+			w.Buffer.AppendString(`\b`)
+		case '\t':
+			w.Buffer.AppendString(`\t`)
+		case '\n':
+			w.Buffer.AppendString(`\n`)
+		case '\f':
+			w.Buffer.AppendString(`\f`)
+		case '\r':
+			w.Buffer.AppendString(`\r`)
+		case '\\':
+			w.Buffer.AppendString(`\\`)
+		case '"':
+			w.Buffer.AppendString(`\"`)
+		default:
+			w.Buffer.AppendString(`\u00`)
+			w.Buffer.AppendByte(chars[c>>4])
+			w.Buffer.AppendByte(chars[c&0xf])
+		}
+		i++
+		p = i
+	}
+	w.Buffer.AppendString(s[p:])
+	w.Buffer.AppendByte('"')
+}
+
+// appendCanonicalFloat appends a number in the ECMAScript format required by RFC 8785, which
+// matches the encoding/json format except for the negative zero.
+func appendCanonicalFloat(b []byte, n float64) []byte {
+	if n == 0 {
+		return append(b, '0')
+	}
+	return appendStdFloat(b, n, 64)
+}
+
+// canonicalRaw writes raw JSON data in the canonical form.
+func (w *Writer) canonicalRaw(data []byte) {
+	l := jlexer.Lexer{Data: data, UseNumber: true}
+	v := l.Interface()
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		w.Error = err
+		return
+	}
+	w.canonicalValue(v)
+}
+
+// canonicalValue writes a value returned by jlexer.Lexer.Interface in the canonical form.
+func (w *Writer) canonicalValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		w.RawString("null")
+	case bool:
+		w.Bool(v)
+	case string:
+		w.canonicalString(v)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			if w.Error == nil {
+				w.Error = fmt.Errorf("jwriter: unsupported value: %v", v)
+			}
+			return
+		}
+		w.Float64(f)
+	case []interface{}:
+		w.RawOpen('[')
+		for i, item := range v {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			w.canonicalValue(item)
+		}
+		w.RawClose(']')
+	case map[string]interface{}:
+		w.RawOpen('{')
+		first := true
+		for key, item := range v {
+			if !first {
+				w.RawByte(',')
+			}
+			first = false
+			w.RawIndent()
+			w.canonicalString(key)
+			w.RawByte(':')
+			w.canonicalValue(item)
+		}
+		w.RawClose('}')
+	}
+}
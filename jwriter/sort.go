@@ -0,0 +1,150 @@
+package jwriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/mailru/easyjson/buffer"
+)
+
+// sortFrame is an object whose members are written to a separate buffer, to be sorted once the
+// object is closed.
+type sortFrame struct {
+	depth   int           // Nesting depth of the object.
+	parent  buffer.Buffer // Buffer of the enclosing value.
+	members []int         // Offsets of the members in the object buffer.
+}
+
+// beginSorted starts writing the members of an object to a separate buffer.
+func (w *Writer) beginSorted() {
+	w.sorted = append(w.sorted, sortFrame{depth: w.depth, parent: w.Buffer})
+	w.Buffer = buffer.Buffer{Pool: w.Buffer.Pool}
+	if w.maxSize > 0 {
+		// The object counts towards the limit together with the enclosing data, including the
+		// data already flushed or discarded.
+		maxSize := w.maxSize
+		for _, frame := range w.sorted {
+			maxSize -= frame.parent.Offset()
+		}
+		if maxSize < 1 {
+			maxSize = 1
+		}
+		w.Buffer.SetMaxSize(maxSize)
+	}
+}
+
+// endSorted writes the object started by beginSorted to the enclosing buffer, with the members
+// sorted by their unescaped names: as sequences of UTF-16 code units in the canonical mode, and
+// as bytes otherwise, the same way as encoding/json sorts map keys.
+func (w *Writer) endSorted() {
+	frame := w.sorted[len(w.sorted)-1]
+	w.sorted = w.sorted[:len(w.sorted)-1]
+
+	if w.Buffer.Exceeded() {
+		w.sizeExceeded()
+	}
+	data := w.Buffer.BuildBytes()
+	w.Buffer = frame.parent
+	if w.Error != nil {
+		// The members may be incomplete, e.g. if the size limit was exceeded.
+		return
+	}
+
+	s := memberSorter{
+		members:   make([][]byte, len(frame.members)),
+		names:     make([]string, len(frame.members)),
+		canonical: w.Canonical,
+	}
+	for i, start := range frame.members {
+		end := len(data)
+		if i+1 < len(frame.members) {
+			end = frame.members[i+1] - 1 // The separating comma.
+		}
+		s.members[i] = data[start:end]
+		s.names[i] = memberName(s.members[i])
+	}
+	sort.Stable(s)
+
+	w.Buffer.AppendByte('{')
+	for i, m := range s.members {
+		if i > 0 {
+			w.Buffer.AppendByte(',')
+		}
+		w.Buffer.AppendBytes(m)
+	}
+	if w.indented() && len(s.members) > 0 {
+		w.newline()
+	}
+	w.Buffer.AppendByte('}')
+}
+
+// memberName returns the unescaped name of an object member.
+func memberName(member []byte) string {
+	start := 0
+	for start < len(member) && member[start] != '"' {
+		start++ // Indentation.
+	}
+	end := start + 1
+	for end < len(member) && member[end] != '"' {
+		if member[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(member) {
+		return ""
+	}
+	name := member[start+1 : end]
+	if bytes.IndexByte(name, '\\') >= 0 {
+		var s string
+		if json.Unmarshal(member[start:end+1], &s) == nil {
+			return s
+		}
+	}
+	return string(name)
+}
+
+// memberSorter sorts object members by their names.
+type memberSorter struct {
+	members   [][]byte
+	names     []string
+	canonical bool // Whether the names are compared as sequences of UTF-16 code units.
+}
+
+func (s memberSorter) Len() int { return len(s.names) }
+
+func (s memberSorter) Swap(i, j int) {
+	s.members[i], s.members[j] = s.members[j], s.members[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
+
+func (s memberSorter) Less(i, j int) bool {
+	if !s.canonical {
+		return s.names[i] < s.names[j]
+	}
+	a, b := s.names[i], s.names[j]
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			// Runes outside the Basic Multilingual Plane start with a high surrogate, the
+			// ones sharing it compare in the rune order.
+			if ua, ub := firstUTF16(ra), firstUTF16(rb); ua != ub {
+				return ua < ub
+			}
+			return ra < rb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// firstUTF16 returns the first UTF-16 code unit of a rune.
+func firstUTF16(r rune) rune {
+	if r < 0x10000 {
+		return r
+	}
+	return 0xd800 + (r-0x10000)>>10
+}
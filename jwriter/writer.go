@@ -2,11 +2,20 @@
 package jwriter
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"math/bits"
 	"strconv"
+	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/mailru/easyjson/buffer"
+	"github.com/mailru/easyjson/internal/word"
 )
 
 // Flags describe various encoding options. The behavior may be actually implemented in the encoder, but
@@ -16,6 +25,16 @@ type Flags int
 const (
 	NilMapAsEmpty   Flags = 1 << iota // Encode nil map as '{}' rather than 'null'.
 	NilSliceAsEmpty                   // Encode nil slice as '[]' rather than 'null'.
+	SortMapKeys                       // Encode map members sorted by their names, as encoding/json does.
+)
+
+// NonFiniteMode defines how NaN and infinite floats, which have no JSON representation, are written.
+type NonFiniteMode int
+
+const (
+	NonFiniteError  NonFiniteMode = iota // Set Error, as encoding/json does.
+	NonFiniteNull                        // Write null.
+	NonFiniteString                      // Write "NaN", "+Inf" or "-Inf" string.
 )
 
 // Writer is a JSON writer.
@@ -25,6 +44,127 @@ type Writer struct {
 	Error        error
 	Buffer       buffer.Buffer
 	NoEscapeHTML bool
+
+	// NonFinite defines how NaN and infinite floats are written, StdFloatFormat makes floats
+	// formatted the same way as encoding/json does. The zero value sets Error; before NonFinite
+	// was added, such floats were written as the invalid JSON tokens NaN, +Inf and -Inf.
+	NonFinite      NonFiniteMode
+	StdFloatFormat bool
+
+	// EscapeNonASCII makes strings escape all the non-ASCII characters as \uXXXX, using UTF-16
+	// surrogate pairs for the characters outside the Basic Multilingual Plane.
+	EscapeNonASCII bool
+
+	// Prefix and Indent make the output indented the same way as json.MarshalIndent does. The
+	// output is compact if both are empty, unless SetIndent is called.
+	Prefix string
+	Indent string
+
+	// ValidateRaw makes Raw check that the data is a single valid JSON value and compact it the
+	// same way as encoding/json does for json.Marshaler results, escaping HTML characters unless
+	// NoEscapeHTML is set. Invalid data sets Error.
+	ValidateRaw bool
+
+	// Canonical makes the output canonical as defined by RFC 8785 (JSON Canonicalization Scheme):
+	// object members are sorted by their names, numbers are written as float64 in the ECMAScript
+	// format, strings are escaped minimally and the output is compact. The other formatting
+	// options are ignored. Objects are sorted only if they are written with RawOpen and RawClose,
+	// as the generated encoders do, while raw JSON, e.g. from json.Marshaler, is reformatted.
+	Canonical bool
+
+	depth  int         // Number of the arrays and objects enclosing the current value.
+	empty  bool        // Whether the innermost array or object of the indented output has no items yet.
+	sorted []sortFrame // Objects whose members are sorted once they are closed.
+
+	scopes []builderScope // Arrays and objects opened by BeginArray and BeginObject.
+
+	maxSize int  // Maximum size of the output, see SetMaxSize.
+	indent  bool // Whether the output is indented even with empty Prefix and Indent, see SetIndent.
+}
+
+// MaxSizeError is the error set when the output exceeds the size given to SetMaxSize.
+type MaxSizeError struct {
+	MaxSize int
+}
+
+func (e *MaxSizeError) Error() string {
+	return fmt.Sprintf("jwriter: output exceeds the maximum size of %d bytes", e.MaxSize)
+}
+
+// SetMaxSize limits the size of the output to about maxSize bytes; zero means no limit. The limit
+// is enforced as the buffer grows, so it may be exceeded by up to a buffer chunk. Once it is
+// exceeded, the output is discarded and Err, BuildBytes, DumpTo, ReadCloser and Flush return a
+// *MaxSizeError.
+func (w *Writer) SetMaxSize(maxSize int) {
+	w.maxSize = maxSize
+	w.Buffer.SetMaxSize(maxSize)
+}
+
+// SetIndent sets Prefix and Indent and makes the output indented even if both are empty, putting
+// each array item and object member on a new line the same way as json.MarshalIndent does.
+func (w *Writer) SetIndent(prefix, indent string) {
+	w.Prefix = prefix
+	w.Indent = indent
+	w.indent = true
+}
+
+// Err returns Error, setting it to a *MaxSizeError first if the output exceeded the size set by
+// SetMaxSize, or to an error if an array or object started by BeginArray or BeginObject is not
+// finished. It is meant to be called once the document is complete.
+func (w *Writer) Err() error {
+	if w.Error == nil && w.Buffer.Exceeded() {
+		w.sizeExceeded()
+	}
+	if n := len(w.scopes); w.Error == nil && n > 0 {
+		if w.scopes[n-1].object {
+			w.builderError("unclosed object")
+		} else {
+			w.builderError("unclosed array")
+		}
+	}
+	return w.Error
+}
+
+// sizeExceeded sets Error after the output exceeded the size set by SetMaxSize.
+func (w *Writer) sizeExceeded() {
+	if w.Error == nil {
+		w.Error = &MaxSizeError{MaxSize: w.maxSize}
+	}
+}
+
+// NewWriter creates a writer that writes the data to out as it is produced: the buffered data is
+// flushed whenever it reaches about flushSize bytes. Errors returned by out are stored in Error.
+// Flush must be called to write the rest of the data once the document is complete.
+func NewWriter(out io.Writer, flushSize int) *Writer {
+	w := &Writer{}
+	w.Buffer.SetOutput(errorWriter{w: w, out: out}, flushSize)
+	return w
+}
+
+// errorWriter stores the errors of the underlying io.Writer in the writer's Error.
+type errorWriter struct {
+	w   *Writer
+	out io.Writer
+}
+
+func (e errorWriter) Write(data []byte) (int, error) {
+	n, err := e.out.Write(data)
+	if err != nil && e.w.Error == nil {
+		e.w.Error = err
+	}
+	return n, err
+}
+
+// Flush writes the buffered data to the io.Writer given to NewWriter and returns Error. If Error
+// is already set, the buffered data is dropped. If the writer was not created by NewWriter, the
+// data is left buffered.
+func (w *Writer) Flush() error {
+	if w.Err() != nil {
+		_, _ = w.Buffer.DumpTo(io.Discard)
+		return w.Error
+	}
+	_ = w.Buffer.Flush()
+	return w.Error
 }
 
 // Size returns the size of the data that was written out.
@@ -32,16 +172,21 @@ func (w *Writer) Size() int {
 	return w.Buffer.Size()
 }
 
-// DumpTo outputs the data to given io.Writer, resetting the buffer.
+// DumpTo outputs the data to given io.Writer, resetting the buffer. If Error is set, e.g. if the
+// output exceeded the size set by SetMaxSize, nothing is written.
 func (w *Writer) DumpTo(out io.Writer) (written int, err error) {
+	if err := w.Err(); err != nil {
+		_, _ = w.Buffer.DumpTo(io.Discard)
+		return 0, err
+	}
 	return w.Buffer.DumpTo(out)
 }
 
 // BuildBytes returns writer data as a single byte slice. You can optionally provide one byte slice
 // as argument that it will try to reuse.
 func (w *Writer) BuildBytes(reuse ...[]byte) ([]byte, error) {
-	if w.Error != nil {
-		return nil, w.Error
+	if err := w.Err(); err != nil {
+		return nil, err
 	}
 
 	return w.Buffer.BuildBytes(reuse...), nil
@@ -50,8 +195,8 @@ func (w *Writer) BuildBytes(reuse ...[]byte) ([]byte, error) {
 // ReadCloser returns an io.ReadCloser that can be used to read the data.
 // ReadCloser also resets the buffer.
 func (w *Writer) ReadCloser() (io.ReadCloser, error) {
-	if w.Error != nil {
-		return nil, w.Error
+	if err := w.Err(); err != nil {
+		return nil, err
 	}
 
 	return w.Buffer.ReadCloser(), nil
@@ -67,6 +212,106 @@ func (w *Writer) RawString(s string) {
 	w.Buffer.AppendString(s)
 }
 
+// indented returns whether the output is indented.
+func (w *Writer) indented() bool {
+	return !w.Canonical && (w.indent || w.Prefix != "" || w.Indent != "")
+}
+
+// newline starts a new line of the indented output at the current depth.
+func (w *Writer) newline() {
+	w.Buffer.AppendByte('\n')
+	w.Buffer.AppendString(w.Prefix)
+	for i := 0; i < w.depth; i++ {
+		w.Buffer.AppendString(w.Indent)
+	}
+}
+
+// RawOpen appends the opening delimiter of an array or object, '[' or '{'.
+func (w *Writer) RawOpen(c byte) {
+	w.depth++
+	if w.Canonical && c == '{' {
+		w.beginSorted()
+		return
+	}
+	if w.indented() {
+		w.empty = true
+	}
+	w.Buffer.AppendByte(c)
+}
+
+// RawOpenMap appends the opening delimiter of an object encoding a map. If sorted is true or
+// SortMapKeys is set, the members are sorted by their unescaped names once the object is closed,
+// the same way as encoding/json sorts map keys.
+func (w *Writer) RawOpenMap(sorted bool) {
+	if !sorted && w.Flags&SortMapKeys == 0 || w.Canonical {
+		w.RawOpen('{')
+		return
+	}
+	w.depth++
+	w.empty = true
+	w.beginSorted()
+}
+
+// RawClose appends the closing delimiter of an array or object, ']' or '}'. If the output is
+// indented and the array or object is not empty, the delimiter is put on a new line.
+func (w *Writer) RawClose(c byte) {
+	if n := len(w.sorted); n > 0 && w.sorted[n-1].depth == w.depth {
+		w.depth--
+		w.endSorted()
+		w.empty = false
+		return
+	}
+	w.depth--
+	if w.indented() {
+		if !w.empty {
+			w.newline()
+		}
+		w.empty = false
+	}
+	w.Buffer.AppendByte(c)
+}
+
+// RawIndent starts a new line for the next array item or object member if the output is
+// indented. It should be called after the separating comma, if any.
+func (w *Writer) RawIndent() {
+	if n := len(w.sorted); n > 0 && w.sorted[n-1].depth == w.depth {
+		w.sorted[n-1].members = append(w.sorted[n-1].members, w.Buffer.Size())
+	}
+	if w.indented() {
+		w.empty = false
+		w.newline()
+	}
+}
+
+// RawColon appends the colon separating an object member name from its value.
+func (w *Writer) RawColon() {
+	if w.indented() {
+		w.Buffer.AppendString(": ")
+		return
+	}
+	w.Buffer.AppendByte(':')
+}
+
+// RawField appends a struct field prefix of the form `,"name":`, the comma being optional, on
+// a new line if the output is indented.
+func (w *Writer) RawField(prefix string) {
+	if w.Canonical {
+		w.canonicalField(prefix)
+		return
+	}
+	if !w.indented() {
+		w.Buffer.AppendString(prefix)
+		return
+	}
+	if prefix[0] == ',' {
+		w.Buffer.AppendByte(',')
+		prefix = prefix[1:]
+	}
+	w.RawIndent()
+	w.Buffer.AppendString(prefix[:len(prefix)-1])
+	w.RawColon()
+}
+
 // RawBytesString appends string from bytes to the buffer.
 func (w *Writer) RawBytesString(data []byte, err error) {
 	switch {
@@ -87,13 +332,43 @@ func (w *Writer) Raw(data []byte, err error) {
 		return
 	case err != nil:
 		w.Error = err
+	case len(data) > 0 && w.Canonical:
+		w.canonicalRaw(data)
+	case len(data) > 0 && w.ValidateRaw:
+		w.rawCompact(data)
 	case len(data) > 0:
-		w.Buffer.AppendBytes(data)
+		w.rawIndented(data)
 	default:
 		w.RawString("null")
 	}
 }
 
+// rawIndented appends raw JSON data, reindenting it if the output is indented.
+func (w *Writer) rawIndented(data []byte) {
+	if w.indented() {
+		var buf bytes.Buffer
+		if json.Indent(&buf, data, w.Prefix+strings.Repeat(w.Indent, w.depth), w.Indent) == nil {
+			data = buf.Bytes()
+		}
+	}
+	w.Buffer.AppendBytes(data)
+}
+
+// rawCompact validates and compacts raw JSON data before appending it.
+func (w *Writer) rawCompact(data []byte) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		w.Error = err
+		return
+	}
+	if !w.NoEscapeHTML {
+		compact := buf.Bytes()
+		buf = bytes.Buffer{}
+		json.HTMLEscape(&buf, compact)
+	}
+	w.rawIndented(buf.Bytes())
+}
+
 // RawText encloses raw binary data in quotes and appends in to the buffer.
 // Useful for calling with results of MarshalText-like functions.
 func (w *Writer) RawText(data []byte, err error) {
@@ -116,7 +391,53 @@ func (w *Writer) Base64Bytes(data []byte) {
 		return
 	}
 	w.Buffer.AppendByte('"')
-	w.base64(data)
+	w.base64(data, encode, true)
+	w.Buffer.AppendByte('"')
+}
+
+// Base64URLBytes appends data to the buffer after encoding it with the URL-safe base64 alphabet,
+// without padding (base64.RawURLEncoding).
+func (w *Writer) Base64URLBytes(data []byte) {
+	if data == nil {
+		w.Buffer.AppendString("null")
+		return
+	}
+	w.Buffer.AppendByte('"')
+	w.base64(data, encodeURL, false)
+	w.Buffer.AppendByte('"')
+}
+
+// Base64RawBytes appends data to the buffer after base64 encoding it without padding
+// (base64.RawStdEncoding).
+func (w *Writer) Base64RawBytes(data []byte) {
+	if data == nil {
+		w.Buffer.AppendString("null")
+		return
+	}
+	w.Buffer.AppendByte('"')
+	w.base64(data, encode, false)
+	w.Buffer.AppendByte('"')
+}
+
+// HexBytes appends data to the buffer after hex encoding it.
+func (w *Writer) HexBytes(data []byte) {
+	if data == nil {
+		w.Buffer.AppendString("null")
+		return
+	}
+	w.Buffer.AppendByte('"')
+	for len(data) > 0 {
+		// Encode as much as fits in the current chunk.
+		w.Buffer.EnsureSpace(2)
+		n := (cap(w.Buffer.Buf) - len(w.Buffer.Buf)) / 2
+		if n > len(data) {
+			n = len(data)
+		}
+		for _, c := range data[:n] {
+			w.Buffer.Buf = append(w.Buffer.Buf, chars[c>>4], chars[c&0xf])
+		}
+		data = data[n:]
+	}
 	w.Buffer.AppendByte('"')
 }
 
@@ -136,11 +457,19 @@ func (w *Writer) Uint32(n uint32) {
 }
 
 func (w *Writer) Uint(n uint) {
+	if w.Canonical && n > maxSafeInteger {
+		w.float(float64(n), 64, false)
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendUint(w.Buffer.Buf, uint64(n), 10)
 }
 
 func (w *Writer) Uint64(n uint64) {
+	if w.Canonical && n > maxSafeInteger {
+		w.float(float64(n), 64, false)
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendUint(w.Buffer.Buf, n, 10)
 }
@@ -161,11 +490,19 @@ func (w *Writer) Int32(n int32) {
 }
 
 func (w *Writer) Int(n int) {
+	if w.Canonical && (n > maxSafeInteger || n < -maxSafeInteger) {
+		w.float(float64(n), 64, false)
+		return
+	}
 	w.Buffer.EnsureSpace(21)
 	w.Buffer.Buf = strconv.AppendInt(w.Buffer.Buf, int64(n), 10)
 }
 
 func (w *Writer) Int64(n int64) {
+	if w.Canonical && (n > maxSafeInteger || n < -maxSafeInteger) {
+		w.float(float64(n), 64, false)
+		return
+	}
 	w.Buffer.EnsureSpace(21)
 	w.Buffer.Buf = strconv.AppendInt(w.Buffer.Buf, n, 10)
 }
@@ -248,27 +585,162 @@ func (w *Writer) Int64Str(n int64) {
 }
 
 func (w *Writer) Float32(n float32) {
-	w.Buffer.EnsureSpace(20)
-	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, float64(n), 'g', -1, 32)
+	w.float(float64(n), 32, false)
 }
 
 func (w *Writer) Float32Str(n float32) {
-	w.Buffer.EnsureSpace(20)
-	w.Buffer.Buf = append(w.Buffer.Buf, '"')
-	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, float64(n), 'g', -1, 32)
-	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.float(float64(n), 32, true)
 }
 
 func (w *Writer) Float64(n float64) {
-	w.Buffer.EnsureSpace(20)
-	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, n, 'g', -1, 64)
+	w.float(n, 64, false)
 }
 
 func (w *Writer) Float64Str(n float64) {
-	w.Buffer.EnsureSpace(20)
-	w.Buffer.Buf = append(w.Buffer.Buf, '"')
-	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, float64(n), 'g', -1, 64)
-	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.float(n, 64, true)
+}
+
+// float appends a float of the given bit size, optionally enclosed in quotes.
+func (w *Writer) float(n float64, bitSize int, quote bool) {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		w.nonFinite(n)
+		return
+	}
+
+	if w.Canonical && bitSize == 32 {
+		// Use the number as it would be read back from the shortest float32 representation.
+		n, _ = strconv.ParseFloat(strconv.FormatFloat(n, 'g', -1, 32), 64)
+	}
+
+	w.Buffer.EnsureSpace(32)
+	if quote {
+		w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	}
+	switch {
+	case w.Canonical:
+		w.Buffer.Buf = appendCanonicalFloat(w.Buffer.Buf, n)
+	case w.StdFloatFormat:
+		w.Buffer.Buf = appendStdFloat(w.Buffer.Buf, n, bitSize)
+	default:
+		w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, n, 'g', -1, bitSize)
+	}
+	if quote {
+		w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	}
+}
+
+// appendStdFloat appends a float formatted the same way as encoding/json does: the exponent
+// notation is used only outside of 1e-6..1e21, and without leading zeroes in the exponent.
+func appendStdFloat(b []byte, n float64, bitSize int) []byte {
+	abs := math.Abs(n)
+	format := byte('f')
+	if abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, n, format, -1, bitSize)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		l := len(b)
+		if l >= 4 && b[l-4] == 'e' && b[l-3] == '-' && b[l-2] == '0' {
+			b[l-2] = b[l-1]
+			b = b[:l-1]
+		}
+	}
+	return b
+}
+
+// nonFinite writes NaN or an infinity according to NonFinite.
+func (w *Writer) nonFinite(n float64) {
+	switch {
+	case w.Canonical:
+		if w.Error == nil {
+			w.Error = fmt.Errorf("jwriter: unsupported value: %v", n)
+		}
+	case w.NonFinite == NonFiniteNull:
+		w.RawString("null")
+	case w.NonFinite == NonFiniteString:
+		w.String(strconv.FormatFloat(n, 'g', -1, 64))
+	default:
+		if w.Error == nil {
+			w.Error = fmt.Errorf("jwriter: unsupported value: %v", n)
+		}
+	}
+}
+
+// BigInt appends an integer of arbitrary size as a JSON number, or null if n is nil.
+func (w *Writer) BigInt(n *big.Int) {
+	if n == nil {
+		w.RawString("null")
+		return
+	}
+	if w.Canonical {
+		f, _ := new(big.Float).SetInt(n).Float64()
+		w.float(f, 64, false)
+		return
+	}
+	w.Buffer.AppendBytes(n.Append(nil, 10))
+}
+
+// BigFloat appends a floating-point number as a JSON number with the shortest decimal
+// representation that keeps its precision, or null if n is nil.
+func (w *Writer) BigFloat(n *big.Float) {
+	switch {
+	case n == nil:
+		w.RawString("null")
+	case n.IsInf() || w.Canonical:
+		f, _ := n.Float64()
+		w.float(f, 64, false)
+	default:
+		w.Buffer.AppendBytes(n.Append(nil, 'g', -1))
+	}
+}
+
+// BigRat appends a rational number as an exact JSON number, or null if n is nil. Numbers
+// without a finite decimal representation, such as 1/3, are reported as an error.
+func (w *Writer) BigRat(n *big.Rat) {
+	if n == nil {
+		w.RawString("null")
+		return
+	}
+	if w.Canonical {
+		f, _ := n.Float64()
+		w.float(f, 64, false)
+		return
+	}
+	if n.IsInt() {
+		w.Buffer.AppendBytes(n.Num().Append(nil, 10))
+		return
+	}
+
+	// The decimal representation is finite only if the denominator is 2^a * 5^b, it needs
+	// max(a, b) digits after the decimal point.
+	d := new(big.Int).Set(n.Denom())
+	twos := d.TrailingZeroBits()
+	d.Rsh(d, twos)
+	fives := uint(0)
+	five := big.NewInt(5)
+	q, m := new(big.Int), new(big.Int)
+	for {
+		if q.QuoRem(d, five, m); m.Sign() != 0 {
+			break
+		}
+		d, q = q, d
+		fives++
+	}
+	if !d.IsInt64() || d.Int64() != 1 {
+		if w.Error == nil {
+			w.Error = fmt.Errorf("jwriter: %v has no exact decimal representation", n)
+		}
+		return
+	}
+
+	digits := twos
+	if fives > digits {
+		digits = fives
+	}
+	w.Buffer.AppendString(n.FloatString(int(digits)))
 }
 
 func (w *Writer) Bool(v bool) {
@@ -301,7 +773,27 @@ var (
 	htmlNoEscapeTable = getTable(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, '"', '\\')
 )
 
+// skipNoEscape returns the index of the first byte of s starting from i that String may not
+// copy as is, checking 8 bytes at a time.
+func skipNoEscape(s string, i int, escapeHTML bool) int {
+	for ; i+8 <= len(s); i += 8 {
+		w := word.LoadString(s, i)
+		m := w&word.MSB | word.HasLess(w, 0x20) | word.HasByte(w, '"') | word.HasByte(w, '\\')
+		if escapeHTML {
+			m |= word.HasByte(w, '<') | word.HasByte(w, '>') | word.HasByte(w, '&')
+		}
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+	}
+	return i
+}
+
 func (w *Writer) String(s string) {
+	if w.Canonical {
+		w.canonicalString(s)
+		return
+	}
 	w.Buffer.AppendByte('"')
 
 	// Portions of the string that contain no escapes are appended as
@@ -315,6 +807,9 @@ func (w *Writer) String(s string) {
 	}
 
 	for i := 0; i < len(s); {
+		if i = skipNoEscape(s, i, !w.NoEscapeHTML); i >= len(s) {
+			break
+		}
 		c := s[i]
 
 		if c < utf8.RuneSelf {
@@ -357,6 +852,19 @@ func (w *Writer) String(s string) {
 			continue
 		}
 
+		if w.EscapeNonASCII {
+			w.Buffer.AppendString(s[p:i])
+			if r1, r2 := utf16.EncodeRune(runeValue); r1 != utf8.RuneError {
+				w.escapeRune(r1)
+				w.escapeRune(r2)
+			} else {
+				w.escapeRune(runeValue)
+			}
+			i += runeWidth
+			p = i
+			continue
+		}
+
 		// jsonp stuff - tab separator and line separator
 		if runeValue == '\u2028' || runeValue == '\u2029' {
 			w.Buffer.AppendString(s[p:i])
@@ -372,27 +880,42 @@ func (w *Writer) String(s string) {
 	w.Buffer.AppendByte('"')
 }
 
+// escapeRune appends a \uXXXX escape sequence for a rune from the Basic Multilingual Plane.
+func (w *Writer) escapeRune(r rune) {
+	w.Buffer.AppendString(`\u`)
+	w.Buffer.AppendByte(chars[r>>12&0xf])
+	w.Buffer.AppendByte(chars[r>>8&0xf])
+	w.Buffer.AppendByte(chars[r>>4&0xf])
+	w.Buffer.AppendByte(chars[r&0xf])
+}
+
 const encode = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+const encodeURL = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
 const padChar = '='
 
-func (w *Writer) base64(in []byte) {
+func (w *Writer) base64(in []byte, encode string, pad bool) {
 
 	if len(in) == 0 {
 		return
 	}
 
-	w.Buffer.EnsureSpace(((len(in)-1)/3 + 1) * 4)
-
 	si := 0
 	n := (len(in) / 3) * 3
 
 	for si < n {
-		// Convert 3x 8bit source bytes into 4 bytes
-		val := uint(in[si+0])<<16 | uint(in[si+1])<<8 | uint(in[si+2])
+		// Encode as many blocks as fit in the current chunk.
+		w.Buffer.EnsureSpace(4)
+		end := si + (cap(w.Buffer.Buf)-len(w.Buffer.Buf))/4*3
+		if end > n {
+			end = n
+		}
 
-		w.Buffer.Buf = append(w.Buffer.Buf, encode[val>>18&0x3F], encode[val>>12&0x3F], encode[val>>6&0x3F], encode[val&0x3F])
+		for ; si < end; si += 3 {
+			// Convert 3x 8bit source bytes into 4 bytes
+			val := uint(in[si+0])<<16 | uint(in[si+1])<<8 | uint(in[si+2])
 
-		si += 3
+			w.Buffer.Buf = append(w.Buffer.Buf, encode[val>>18&0x3F], encode[val>>12&0x3F], encode[val>>6&0x3F], encode[val&0x3F])
+		}
 	}
 
 	remain := len(in) - si
@@ -401,6 +924,7 @@ func (w *Writer) base64(in []byte) {
 	}
 
 	// Add the remaining small block
+	w.Buffer.EnsureSpace(4)
 	val := uint(in[si+0]) << 16
 	if remain == 2 {
 		val |= uint(in[si+1]) << 8
@@ -408,10 +932,13 @@ func (w *Writer) base64(in []byte) {
 
 	w.Buffer.Buf = append(w.Buffer.Buf, encode[val>>18&0x3F], encode[val>>12&0x3F])
 
-	switch remain {
-	case 2:
-		w.Buffer.Buf = append(w.Buffer.Buf, encode[val>>6&0x3F], byte(padChar))
-	case 1:
+	switch {
+	case remain == 2:
+		w.Buffer.Buf = append(w.Buffer.Buf, encode[val>>6&0x3F])
+		if pad {
+			w.Buffer.Buf = append(w.Buffer.Buf, byte(padChar))
+		}
+	case pad:
 		w.Buffer.Buf = append(w.Buffer.Buf, byte(padChar), byte(padChar))
 	}
 }
@@ -0,0 +1,83 @@
+package easyjson
+
+import (
+	"io"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// StreamDecoder reads a stream of whitespace-separated JSON values, e.g. newline-delimited
+// JSON, from an io.Reader. The input is read incrementally, so only the currently decoded
+// part of the stream is held in memory.
+type StreamDecoder struct {
+	lexer jlexer.Lexer
+}
+
+// NewStreamDecoder returns a StreamDecoder that reads from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{
+		lexer: jlexer.Lexer{Reader: r, MultipleValues: true},
+	}
+}
+
+// Decode decodes the next JSON value from the stream into the object. It returns io.EOF if
+// there are no more values in the stream. Errors are sticky: once a value fails to decode,
+// all the following calls return the same error.
+func (d *StreamDecoder) Decode(v Unmarshaler) error {
+	if err := d.err(); err != nil {
+		return err
+	}
+	if d.lexer.IsEnd() {
+		if err := d.err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	v.UnmarshalEasyJSON(&d.lexer)
+	d.lexer.Consumed()
+	return d.err()
+}
+
+// err returns the lexer error, reporting the end of input in the middle of a value as
+// io.ErrUnexpectedEOF.
+func (d *StreamDecoder) err() error {
+	err := d.lexer.Error()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// StreamEncoder writes a stream of newline-delimited JSON values to an io.Writer.
+type StreamEncoder struct {
+	w      io.Writer
+	writer jwriter.Writer
+}
+
+// NewStreamEncoder returns a StreamEncoder that writes to w.
+func NewStreamEncoder(w io.Writer) *StreamEncoder {
+	return &StreamEncoder{w: w}
+}
+
+// Encode writes the JSON encoding of the object followed by a newline to the stream. The
+// data is flushed to the underlying writer after each value.
+func (e *StreamEncoder) Encode(v Marshaler) error {
+	if isNilInterface(v) {
+		e.writer.Raw(nullBytes, nil)
+	} else {
+		v.MarshalEasyJSON(&e.writer)
+	}
+
+	if err := e.writer.Err(); err != nil {
+		// Drop the partially encoded value, returning its chunks to the pool.
+		_, _ = e.writer.Buffer.DumpTo(io.Discard)
+		e.writer = jwriter.Writer{}
+		return err
+	}
+
+	e.writer.RawByte('\n')
+	_, err := e.writer.DumpTo(e.w)
+	return err
+}
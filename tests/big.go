@@ -0,0 +1,14 @@
+package tests
+
+import "math/big"
+
+//easyjson:json
+type BigNumbers struct {
+	Int      *big.Int
+	Float    *big.Float
+	Rat      *big.Rat
+	IntValue big.Int
+	Nil      *big.Int
+	Slice    []*big.Rat
+	Map      map[string]*big.Float
+}
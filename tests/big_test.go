@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestBigNumbers(t *testing.T) {
+	data := `{"Int":123456789012345678901234567890,"Float":0.10000000000000000000000000001,"Rat":-12.5e-3,` +
+		`"IntValue":-9223372036854775809,"Nil":null,"Slice":[1,0.5],"Map":{"a":1e+100}}`
+
+	var v BigNumbers
+	if err := easyjson.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if got, want := v.Int.String(), "123456789012345678901234567890"; got != want {
+		t.Errorf("Int = %s; want %s", got, want)
+	}
+	if got, want := v.Float.Text('g', -1), "0.10000000000000000000000000001"; got != want {
+		t.Errorf("Float = %s; want %s", got, want)
+	}
+	if got, want := v.Rat.RatString(), "-1/80"; got != want {
+		t.Errorf("Rat = %s; want %s", got, want)
+	}
+	if v.Nil != nil {
+		t.Errorf("Nil = %v; want nil", v.Nil)
+	}
+
+	got, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	want := `{"Int":123456789012345678901234567890,"Float":0.10000000000000000000000000001,"Rat":-0.0125,` +
+		`"IntValue":-9223372036854775809,"Nil":null,"Slice":[1,0.5],"Map":{"a":1e+100}}`
+	if string(got) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", got, want)
+	}
+
+	if err := easyjson.Unmarshal([]byte(`{"Int":1.5}`), &v); err == nil {
+		t.Errorf("easyjson.Unmarshal() of a fractional big.Int ok; want error")
+	}
+	if _, err := easyjson.Marshal(BigNumbers{Rat: big.NewRat(1, 3)}); err == nil {
+		t.Errorf("easyjson.Marshal() of 1/3 ok; want error")
+	}
+}
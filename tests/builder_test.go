@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func buildDocument(w *jwriter.Writer) {
+	w.BeginObject()
+	w.Key("name")
+	w.String("x")
+	w.Key("tags")
+	w.BeginArray()
+	for _, tag := range []string{"a", "b"} {
+		w.Item()
+		w.String(tag)
+	}
+	w.EndArray()
+	w.Key("empty")
+	w.BeginArray()
+	w.EndArray()
+	w.Key("nested")
+	w.BeginObject()
+	w.Key("b")
+	w.Int(2)
+	w.Key("a")
+	w.BeginObject()
+	w.EndObject()
+	w.EndObject()
+	w.EndObject()
+}
+
+func TestBuilder(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		w    jwriter.Writer
+		want string
+	}{
+		{
+			name: "compact",
+			want: `{"name":"x","tags":["a","b"],"empty":[],"nested":{"b":2,"a":{}}}`,
+		},
+		{
+			name: "indented",
+			w:    jwriter.Writer{Indent: "  "},
+			want: "{\n" +
+				"  \"name\": \"x\",\n" +
+				"  \"tags\": [\n" +
+				"    \"a\",\n" +
+				"    \"b\"\n" +
+				"  ],\n" +
+				"  \"empty\": [],\n" +
+				"  \"nested\": {\n" +
+				"    \"b\": 2,\n" +
+				"    \"a\": {}\n" +
+				"  }\n" +
+				"}",
+		},
+		{
+			name: "canonical",
+			w:    jwriter.Writer{Canonical: true},
+			want: `{"empty":[],"name":"x","nested":{"a":{},"b":2},"tags":["a","b"]}`,
+		},
+	} {
+		buildDocument(&test.w)
+		got, err := test.w.BuildBytes()
+		if err != nil {
+			t.Errorf("%s: BuildBytes() error: %v", test.name, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBuilderMisuse(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		build func(w *jwriter.Writer)
+		want  string
+	}{
+		{
+			name:  "key at top level",
+			build: func(w *jwriter.Writer) { w.Key("a") },
+			want:  "jwriter: Key called outside of an object",
+		},
+		{
+			name: "key in array",
+			build: func(w *jwriter.Writer) {
+				w.BeginArray()
+				w.Key("a")
+			},
+			want: "jwriter: Key called outside of an object",
+		},
+		{
+			name: "item in object",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Item()
+			},
+			want: "jwriter: Item called outside of an array",
+		},
+		{
+			name:  "unmatched end",
+			build: func(w *jwriter.Writer) { w.EndObject() },
+			want:  "jwriter: EndObject called outside of an object",
+		},
+		{
+			name: "mismatched end",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.EndArray()
+				w.EndObject()
+			},
+			want: "jwriter: EndArray called outside of an array",
+		},
+		{
+			name: "key after key",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Key("a")
+				w.Key("b")
+				w.Int(1)
+				w.EndObject()
+			},
+			want: "jwriter: Key called with no value after Key",
+		},
+		{
+			name: "end after key",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Key("a")
+				w.EndObject()
+			},
+			want: "jwriter: EndObject called with no value after Key",
+		},
+		{
+			name: "value in object without key",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Int(1)
+				w.EndObject()
+			},
+			want: "jwriter: value written in an object without Key",
+		},
+		{
+			name: "values in array without item",
+			build: func(w *jwriter.Writer) {
+				w.BeginArray()
+				w.String("a")
+				w.String("b")
+				w.EndArray()
+			},
+			want: "jwriter: value written in an array without Item",
+		},
+		{
+			name: "nested array without item",
+			build: func(w *jwriter.Writer) {
+				w.BeginArray()
+				w.BeginArray()
+				w.EndArray()
+				w.Item()
+				w.Int(1)
+				w.EndArray()
+			},
+			want: "jwriter: value written in an array without Item",
+		},
+		{
+			name: "item after item",
+			build: func(w *jwriter.Writer) {
+				w.BeginArray()
+				w.Item()
+				w.Item()
+				w.Int(1)
+				w.EndArray()
+			},
+			want: "jwriter: Item called with no value after Item",
+		},
+		{
+			name: "end after item",
+			build: func(w *jwriter.Writer) {
+				w.Indent = "  "
+				w.BeginArray()
+				w.Item()
+				w.EndArray()
+			},
+			want: "jwriter: EndArray called with no value after Item",
+		},
+	} {
+		var w jwriter.Writer
+		test.build(&w)
+		if w.Error == nil || w.Error.Error() != test.want {
+			t.Errorf("%s: got error %v, want %q", test.name, w.Error, test.want)
+		}
+	}
+}
+
+func TestBuilderStream(t *testing.T) {
+	var out bytes.Buffer
+	w := jwriter.NewWriter(&out, 16)
+	w.BeginArray()
+	for i := 0; i < 1000; i++ {
+		w.Item()
+		w.BeginObject()
+		w.Key("i")
+		w.Int(i)
+		w.EndObject()
+	}
+	w.EndArray()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+
+	var got []struct{ I int }
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if len(got) != 1000 || got[999].I != 999 {
+		t.Errorf("got %d items, want 1000", len(got))
+	}
+}
+
+func TestBuilderUnclosed(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		build func(w *jwriter.Writer)
+		want  string
+	}{
+		{
+			name: "object",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Key("a")
+				w.Int(1)
+			},
+			want: "jwriter: unclosed object",
+		},
+		{
+			name: "array",
+			build: func(w *jwriter.Writer) {
+				w.BeginObject()
+				w.Key("a")
+				w.BeginArray()
+				w.Item()
+				w.Int(1)
+			},
+			want: "jwriter: unclosed array",
+		},
+	} {
+		var w jwriter.Writer
+		test.build(&w)
+		got, err := w.BuildBytes()
+		if err == nil || err.Error() != test.want || got != nil {
+			t.Errorf("%s: BuildBytes() = %s, %v, want error %q", test.name, got, err, test.want)
+		}
+
+		var out bytes.Buffer
+		w = jwriter.Writer{}
+		test.build(&w)
+		if _, err := w.DumpTo(&out); err == nil || err.Error() != test.want || out.Len() != 0 {
+			t.Errorf("%s: DumpTo() error %v with %v bytes written, want %q", test.name, err, out.Len(), test.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package tests
+
+//easyjson:json
+type BytesEncodings struct {
+	Std    []byte   `json:"std"`
+	URL    []byte   `json:"url,base64url"`
+	Raw    []byte   `json:"raw,base64raw"`
+	Hex    []byte   `json:"hex,hex"`
+	Array  [3]byte  `json:"array,hex"`
+	Slices [][]byte `json:"slices,base64url"`
+}
@@ -0,0 +1,7 @@
+package tests
+
+//easyjson:json
+type BytesDefaultEncoding struct {
+	Hex []byte
+	Std []byte `json:",base64"`
+}
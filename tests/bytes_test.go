@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestBytesEncodings(t *testing.T) {
+	v := BytesEncodings{
+		Std:    []byte{0xfb, 0xff},
+		URL:    []byte{0xfb, 0xff},
+		Raw:    []byte{0xfb, 0xff},
+		Hex:    []byte{0xfb, 0xff},
+		Array:  [3]byte{1, 2, 0xab},
+		Slices: [][]byte{{0xfb, 0xff}, nil},
+	}
+	want := `{"std":"+/8=","url":"-_8","raw":"+/8","hex":"fbff","array":"0102ab","slices":["-_8",null]}`
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+
+	var got BytesEncodings
+	if err := easyjson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("easyjson.Unmarshal() = %+v; want %+v", got, v)
+	}
+
+	for _, data := range []string{`{"url":"+/8="}`, `{"std":"-_8"}`, `{"hex":"zz"}`} {
+		if err := easyjson.Unmarshal([]byte(data), &got); err == nil {
+			t.Errorf("easyjson.Unmarshal(%s) expected error", data)
+		}
+	}
+}
+
+func TestLenientBase64(t *testing.T) {
+	for _, data := range []string{
+		`{"std":"+/8=","url":"+/8=","raw":"+/8="}`,
+		`{"std":"-_8","url":"-_8","raw":"-_8"}`,
+		`{"std":"+/8","url":"-_8=","raw":"-/8"}`,
+	} {
+		l := jlexer.Lexer{Data: []byte(data), LenientBase64: true}
+		var got BytesEncodings
+		got.UnmarshalEasyJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Errorf("UnmarshalEasyJSON(%s) error: %v", data, err)
+			continue
+		}
+		want := []byte{0xfb, 0xff}
+		if !reflect.DeepEqual(got.Std, want) || !reflect.DeepEqual(got.URL, want) || !reflect.DeepEqual(got.Raw, want) {
+			t.Errorf("UnmarshalEasyJSON(%s) = %+v; want %v in each field", data, got, want)
+		}
+	}
+}
+
+func TestBytesDefaultEncoding(t *testing.T) {
+	data, err := easyjson.Marshal(BytesDefaultEncoding{Hex: []byte{0xfb, 0xff}, Std: []byte{0xfb, 0xff}})
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	if want := `{"Hex":"fbff","Std":"+/8="}`; string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+}
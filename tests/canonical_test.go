@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func TestCanonicalRFC8785(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{
+			in: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			in: `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh",
+				"1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control",
+				"\u00f6": "Latin Small Letter O With Diaeresis"}`,
+			want: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"דּ\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			in:   `[-0, 1e21, 1e-7, 9007199254740993, "\b\f\u007f\u2028<>&", {}, [], {"b": {"d": 1, "c": 2}, "a": []}]`,
+			want: `[0,1e+21,1e-7,9007199254740992,"\b\f` + "\u007f\u2028<>&" + `",{},[],{"a":[],"b":{"c":2,"d":1}}]`,
+		},
+	} {
+		w := jwriter.Writer{Canonical: true}
+		w.Raw([]byte(test.in), nil)
+		got, err := w.BuildBytes()
+		if err != nil {
+			t.Errorf("Raw(%s) error: %v", test.in, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("Raw(%s) = %s; want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for i, test := range testCases {
+		w := jwriter.Writer{Canonical: true}
+		test.Decoded.(easyjson.Marshaler).MarshalEasyJSON(&w)
+		got, err := w.BuildBytes()
+		if err != nil {
+			t.Errorf("[%d, %T] MarshalEasyJSON() error: %v", i, test.Decoded, err)
+		}
+
+		w = jwriter.Writer{Canonical: true}
+		w.Raw([]byte(test.Encoded), nil)
+		want, err := w.BuildBytes()
+		if err != nil {
+			t.Errorf("[%d, %T] Raw() error: %v", i, test.Decoded, err)
+		}
+
+		if string(got) != string(want) {
+			t.Errorf("[%d, %T] MarshalEasyJSON(): got \n%s\n\t\t want \n%s", i, test.Decoded, got, want)
+		}
+	}
+}
+
+func TestCanonicalFloats(t *testing.T) {
+	w := jwriter.Writer{Canonical: true}
+	w.Float64Str(0)
+	w.RawByte(',')
+	w.Float32(float32(1.1))
+	if got, _ := w.BuildBytes(); string(got) != `"0",1.1` {
+		t.Errorf("got %s; want \"0\",1.1", got)
+	}
+}
+
+func TestCanonicalNonFinite(t *testing.T) {
+	for _, mode := range []jwriter.NonFiniteMode{jwriter.NonFiniteError, jwriter.NonFiniteNull, jwriter.NonFiniteString} {
+		for _, n := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			for _, write := range []func(w *jwriter.Writer){
+				func(w *jwriter.Writer) { w.Float64(n) },
+				func(w *jwriter.Writer) { w.Float32(float32(n)) },
+				func(w *jwriter.Writer) { w.Float64Str(n) },
+			} {
+				w := jwriter.Writer{Canonical: true, NonFinite: mode}
+				write(&w)
+				if w.Error == nil {
+					t.Errorf("NonFinite %v, %v: no error", mode, n)
+				}
+				if size := w.Size(); size != 0 {
+					t.Errorf("NonFinite %v, %v: %v bytes written", mode, n, size)
+				}
+			}
+		}
+	}
+}
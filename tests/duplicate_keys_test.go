@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	for i, test := range []struct {
+		Data    string
+		Value   easyjson.Unmarshaler
+		Offsets []int
+	}{
+		{
+			Data:  `{"Field1":"a","Field2":"b","Field3":"c"}`,
+			Value: &StructWithUnknownsProxy{},
+		},
+		{
+			Data:    `{"Field1":"a","Field1":"b"}`,
+			Value:   &StructWithUnknownsProxy{},
+			Offsets: []int{14},
+		},
+		{
+			Data:    `{"Field2":"a","Field1":"b","Field2":"c"}`,
+			Value:   &StructWithUnknownsProxy{},
+			Offsets: []int{27},
+		},
+		{
+			Data:    `{"a":"b","c":"d","a":"e"}`,
+			Value:   &MapStringString{},
+			Offsets: []int{17},
+		},
+		{
+			Data:    `{"1":"a","2":"b","1":"c"}`,
+			Value:   &ErrorIntMap{},
+			Offsets: []int{17},
+		},
+		{
+			Data:    `{"Value":{"a":1,"a":2},"Map":{"b":{"c":1,"c":2}},"Map":{}}`,
+			Value:   &NestedInterfaces{},
+			Offsets: []int{16, 41, 49},
+		},
+	} {
+		l := jlexer.Lexer{
+			Data:                  []byte(test.Data),
+			DisallowDuplicateKeys: true,
+			UseMultipleErrors:     true,
+		}
+		test.Value.UnmarshalEasyJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d] TestDuplicateKeys(): error: %v", i, err)
+			continue
+		}
+
+		errors := l.GetNonFatalErrors()
+		if len(errors) != len(test.Offsets) {
+			t.Errorf("[%d] TestDuplicateKeys(): errornum: want: %d, got %d", i, len(test.Offsets), len(errors))
+			continue
+		}
+		for ii, e := range errors {
+			if e.Reason != jlexer.ReasonDuplicateKey || e.Offset != test.Offsets[ii] {
+				t.Errorf("[%d] TestDuplicateKeys(): error[%d]: %v; want duplicate key at offset %d", i, ii, e, test.Offsets[ii])
+			}
+		}
+	}
+
+	l := jlexer.Lexer{Data: []byte(`{"a":"b","a":"c"}`), DisallowDuplicateKeys: true}
+	var v MapStringString
+	v.UnmarshalEasyJSON(&l)
+	if err, ok := l.Error().(*jlexer.LexerError); !ok || err.Reason != jlexer.ReasonDuplicateKey {
+		t.Errorf("TestDuplicateKeys(): error: %v; want %q", l.Error(), jlexer.ReasonDuplicateKey)
+	}
+}
@@ -283,3 +283,46 @@ func TestMultipleErrorsIntMap(t *testing.T) {
 		}
 	}
 }
+
+func TestMultipleErrorsPath(t *testing.T) {
+	for i, test := range []struct {
+		Data  []byte
+		Paths []string
+	}{
+		{
+			Data:  []byte(`{"error_struct":5}`),
+			Paths: []string{"$.error_struct"},
+		},
+		{
+			Data:  []byte(`{"error_struct":{"int_slice":{}}, "int":4}`),
+			Paths: []string{"$.error_struct.int_slice"},
+		},
+		{
+			Data:  []byte("{\"error_struct\":{\"int_slice\":[\"1\", 2,\n \"3\"]}, \"int\":[]}"),
+			Paths: []string{"$.error_struct.int_slice[0]", "$.error_struct.int_slice[2]", "$.int"},
+		},
+	} {
+		l := jlexer.Lexer{
+			Data:              test.Data,
+			UseMultipleErrors: true,
+			TrackPath:         true,
+		}
+		var v ErrorNestedStruct
+		v.UnmarshalEasyJSON(&l)
+
+		errors := l.GetNonFatalErrors()
+
+		if len(errors) != len(test.Paths) {
+			t.Errorf("[%d] TestMultipleErrorsPath(): errornum: want: %d, got %d", i, len(test.Paths), len(errors))
+			return
+		}
+		for ii, e := range errors {
+			if e.Path != test.Paths[ii] {
+				t.Errorf("[%d] TestMultipleErrorsPath(): path[%d]: want %s, got %s", i, ii, test.Paths[ii], e.Path)
+			}
+			if e.Line == 0 {
+				t.Errorf("[%d] TestMultipleErrorsPath(): line[%d] is not set", i, ii)
+			}
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
 )
 
 func TestStrFieldsUnescaping(t *testing.T) {
@@ -88,3 +92,45 @@ func TestIntFieldsUnescaping(t *testing.T) {
 		}
 	}
 }
+
+func TestStrFieldsEscaping(t *testing.T) {
+	// Put each special character at every position of a string longer than a word.
+	for _, special := range []string{"\"", "\\", "\n", "\x01", "\x1f", "<", ">", "&", "é", "\u2028", "\U0001F600", "\xff", "\x7f"} {
+		for i := 0; i <= 17; i++ {
+			s := strings.Repeat("a", i) + special + strings.Repeat("b", 17-i)
+
+			for _, noEscapeHTML := range []bool{false, true} {
+				for _, escapeNonASCII := range []bool{false, true} {
+					w := jwriter.Writer{NoEscapeHTML: noEscapeHTML, EscapeNonASCII: escapeNonASCII}
+					EscStringStruct{A: s}.MarshalEasyJSON(&w)
+					data, err := w.BuildBytes()
+					if err != nil {
+						t.Fatalf("MarshalEasyJSON() error: %v", err)
+					}
+
+					var got EscStringStruct
+					if err := json.Unmarshal(data, &got); err != nil {
+						t.Errorf("[%q] json.Unmarshal(%s) error: %v", s, data, err)
+					} else if want := strings.ToValidUTF8(s, "\ufffd"); got.A != want {
+						t.Errorf("[%q] MarshalEasyJSON() = %s; want %q", s, data, want)
+					}
+					if !noEscapeHTML && strings.ContainsAny(string(data), "<>&") {
+						t.Errorf("[%q] MarshalEasyJSON() = %s; want HTML characters escaped", s, data)
+					}
+					if escapeNonASCII && strings.IndexFunc(string(data), func(r rune) bool { return r >= utf8.RuneSelf }) >= 0 {
+						t.Errorf("[%q] MarshalEasyJSON() = %s; want non-ASCII characters escaped", s, data)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEscapeNonASCII(t *testing.T) {
+	w := jwriter.Writer{EscapeNonASCII: true}
+	w.String("a\u00e9\u2028\U0001F600\xffz")
+	want := `"a\u00e9\u2028\ud83d\ude00\ufffdz"`
+	if got, _ := w.BuildBytes(); string(got) != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+}
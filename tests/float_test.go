@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func TestStdFloatFormat(t *testing.T) {
+	values := []float64{0, math.Copysign(0, -1), 1, -1, 0.1, 1e6, 1e-6, 9.99999e-7, 1e20, 1e21, 123456789e13, 1e-7,
+		1.5e-9, 1e100, math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat32, math.SmallestNonzeroFloat32}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		values = append(values, math.Float64frombits(r.Uint64()))
+	}
+
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+
+		w := jwriter.Writer{StdFloatFormat: true}
+		w.Float64(v)
+		want, _ := json.Marshal(v)
+		if got, _ := w.BuildBytes(); string(got) != string(want) {
+			t.Errorf("Float64(%v) = %s; want %s", v, got, want)
+		}
+
+		w.Float64Str(v)
+		want, _ = json.Marshal(struct {
+			V float64 `json:",string"`
+		}{v})
+		if got, _ := w.BuildBytes(); `{"V":`+string(got)+`}` != string(want) {
+			t.Errorf("Float64Str(%v) = %s; want %s", v, got, want)
+		}
+
+		v32 := float32(v)
+		if math.IsInf(float64(v32), 0) {
+			continue
+		}
+		w.Float32(v32)
+		want, _ = json.Marshal(v32)
+		if got, _ := w.BuildBytes(); string(got) != string(want) {
+			t.Errorf("Float32(%v) = %s; want %s", v32, got, want)
+		}
+	}
+}
+
+func TestNonFiniteFloats(t *testing.T) {
+	for _, test := range []struct {
+		mode    jwriter.NonFiniteMode
+		want    string
+		wantErr bool
+	}{
+		{mode: jwriter.NonFiniteError, wantErr: true},
+		{mode: jwriter.NonFiniteNull, want: `[null,null,null,null,null]`},
+		{mode: jwriter.NonFiniteString, want: `["NaN","+Inf","-Inf","+Inf","-Inf"]`},
+	} {
+		w := jwriter.Writer{NonFinite: test.mode}
+		w.RawByte('[')
+		w.Float64(math.NaN())
+		w.RawByte(',')
+		w.Float32(float32(math.Inf(1)))
+		w.RawByte(',')
+		w.Float64Str(math.Inf(-1))
+		w.RawByte(',')
+		w.BigFloat(new(big.Float).SetInf(false))
+		w.RawByte(',')
+		w.Float32Str(float32(math.Inf(-1)))
+		w.RawByte(']')
+
+		got, err := w.BuildBytes()
+		if (err != nil) != test.wantErr {
+			t.Errorf("[%v] error: %v; want error %v", test.mode, err, test.wantErr)
+		}
+		if !test.wantErr && string(got) != test.want {
+			t.Errorf("[%v] got %s; want %s", test.mode, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func TestMarshalIndent(t *testing.T) {
+	for i, test := range testCases {
+		var want bytes.Buffer
+		if err := json.Indent(&want, []byte(test.Encoded), ">", "\t"); err != nil {
+			t.Fatalf("[%d, %T] json.Indent() error: %v", i, test.Decoded, err)
+		}
+
+		got, err := easyjson.MarshalIndent(test.Decoded.(easyjson.Marshaler), ">", "\t")
+		if err != nil {
+			t.Errorf("[%d, %T] easyjson.MarshalIndent() error: %v", i, test.Decoded, err)
+		}
+		if string(got) != want.String() {
+			t.Errorf("[%d, %T] easyjson.MarshalIndent(): got \n%s\n\t\t want \n%s", i, test.Decoded, got, want.String())
+		}
+	}
+}
+
+func TestMarshalIndentStd(t *testing.T) {
+	v := NestedInterfaces{
+		Value: map[string]interface{}{"a": []int{1, 2}, "b": struct{}{}},
+		Slice: []interface{}{},
+		Map:   map[string]interface{}{"c": nil},
+	}
+	want, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error: %v", err)
+	}
+	got, err := easyjson.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Errorf("easyjson.MarshalIndent() error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("easyjson.MarshalIndent(): got \n%s\n\t\t want \n%s", got, want)
+	}
+}
+
+func TestMarshalIndentEmpty(t *testing.T) {
+	v := NestedInterfaces{
+		Value: map[string]interface{}{"a": []int{1, 2}, "b": struct{}{}},
+		Slice: []interface{}{},
+		Map:   map[string]interface{}{"c": nil},
+	}
+	want, err := json.MarshalIndent(v, "", "")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error: %v", err)
+	}
+	got, err := easyjson.MarshalIndent(v, "", "")
+	if err != nil {
+		t.Errorf("easyjson.MarshalIndent() error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("easyjson.MarshalIndent(): got \n%s\n\t\t want \n%s", got, want)
+	}
+}
+
+func TestWriterIndent(t *testing.T) {
+	w := jwriter.Writer{Indent: " "}
+	w.RawOpen('[')
+	w.RawIndent()
+	w.RawOpen('{')
+	w.RawClose('}')
+	w.RawByte(',')
+	w.RawIndent()
+	w.RawOpen('{')
+	w.RawField(`"a":`)
+	w.Raw([]byte(`[1,{}]`), nil)
+	w.RawClose('}')
+	w.RawClose(']')
+
+	want := "[\n {},\n {\n  \"a\": [\n   1,\n   {}\n  ]\n }\n]"
+	if got, _ := w.BuildBytes(); string(got) != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
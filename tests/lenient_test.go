@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestLenientConfig(t *testing.T) {
+	data := []byte(`// service config
+{
+	error_struct: {
+		int: 1,
+		string: 'single "quoted"',
+		slice: [1, 2,], /* trailing comma */
+		unknown: {nested: [']', /* ] */ 3,],},
+	},
+	int: 2, // trailing comma
+}
+`)
+	want := ErrorNestedStruct{
+		ErrorStruct: ErrorStruct{Int: 1, String: `single "quoted"`, Slice: []int{1, 2}},
+		Int:         2,
+	}
+
+	l := jlexer.Lexer{Data: data, Lenient: true}
+	var got ErrorNestedStruct
+	got.UnmarshalEasyJSON(&l)
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("UnmarshalEasyJSON() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalEasyJSON() = %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestLimits(t *testing.T) {
+	for i, test := range []struct {
+		Data   string
+		Lexer  jlexer.Lexer
+		Reason string
+	}{
+		{
+			Data:  `{"error_struct":{"int_slice":[1,2],"string":"abcd"},"int":1}`,
+			Lexer: jlexer.Lexer{MaxDepth: 3, MaxStringLen: 12, MaxArrayLen: 2, MaxObjectKeys: 4},
+		},
+		{
+			Data:   `{"error_struct":{"unknown":[[[]]]}}`,
+			Lexer:  jlexer.Lexer{MaxDepth: 4},
+			Reason: jlexer.ReasonMaxDepth,
+		},
+		{
+			Data:   `{"error_struct":{"string":"abcdef"}}`,
+			Lexer:  jlexer.Lexer{MaxStringLen: 5},
+			Reason: jlexer.ReasonMaxStringLen,
+		},
+		{
+			Data:   `{"error_struct":{"int_slice":[1,2,3]}}`,
+			Lexer:  jlexer.Lexer{MaxArrayLen: 2},
+			Reason: jlexer.ReasonMaxArrayLen,
+		},
+		{
+			Data:   `{"error_struct":{},"int":1,"unknown":2}`,
+			Lexer:  jlexer.Lexer{MaxObjectKeys: 2},
+			Reason: jlexer.ReasonMaxObjectKeys,
+		},
+	} {
+		for _, multipleErrors := range []bool{false, true} {
+			l := test.Lexer
+			l.Data = []byte(test.Data)
+			l.UseMultipleErrors = multipleErrors
+
+			var v ErrorNestedStruct
+			v.UnmarshalEasyJSON(&l)
+
+			err := l.Error()
+			if test.Reason == "" {
+				if err != nil {
+					t.Errorf("[%d] TestLimits(): error: %v", i, err)
+				}
+				continue
+			}
+			if lerr, ok := err.(*jlexer.LexerError); !ok || lerr.Reason != test.Reason {
+				t.Errorf("[%d] TestLimits(): error: %v; want %q", i, err, test.Reason)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// largeSlice is encoded as about 200 KB of JSON.
+var largeSlice = func() EncodingFlagsTestSlice {
+	v := EncodingFlagsTestSlice{F: make([]string, 20000)}
+	for i := range v.F {
+		v.F[i] = "0123456789"
+	}
+	return v
+}()
+
+func isMaxSizeError(err error, maxSize int) bool {
+	e, ok := err.(*jwriter.MaxSizeError)
+	return ok && e.MaxSize == maxSize
+}
+
+func TestWriterMaxSize(t *testing.T) {
+	w := jwriter.Writer{}
+	w.SetMaxSize(1000)
+	largeSlice.MarshalEasyJSON(&w)
+	if err := w.Err(); !isMaxSizeError(err, 1000) {
+		t.Errorf("got error %v, want *MaxSizeError", err)
+	}
+	if size := w.Size(); size > 1000+32768 {
+		t.Errorf("Size() = %v after exceeding the limit", size)
+	}
+
+	w = jwriter.Writer{}
+	w.SetMaxSize(1000)
+	EncodingFlagsTestSlice{F: []string{"a", "b"}}.MarshalEasyJSON(&w)
+	if got, err := w.BuildBytes(); err != nil || string(got) != `{"F":["a","b"]}` {
+		t.Errorf("BuildBytes() = %s, %v within the limit", got, err)
+	}
+}
+
+func TestWriterMaxSizeSorted(t *testing.T) {
+	v := SortedMaps{Strings: map[string]int{}}
+	for i := 0; i < 20000; i++ {
+		v.Strings[strings.Repeat("k", i%50)+string(rune('a'+i%26))+strings.Repeat("x", i/26%30)] = i
+	}
+	w := jwriter.Writer{}
+	w.SetMaxSize(1000)
+	v.MarshalEasyJSON(&w)
+	if err := w.Err(); !isMaxSizeError(err, 1000) {
+		t.Errorf("got error %v, want *MaxSizeError", err)
+	}
+}
+
+func TestWriterMaxSizeBytes(t *testing.T) {
+	data := make([]byte, 10<<20)
+	for _, v := range []BytesEncodings{{Std: data}, {URL: data}, {Raw: data}, {Hex: data}} {
+		w := jwriter.Writer{}
+		w.SetMaxSize(1000)
+		v.MarshalEasyJSON(&w)
+		if err := w.Err(); !isMaxSizeError(err, 1000) {
+			t.Errorf("got error %v, want *MaxSizeError", err)
+		}
+		if size := w.Size(); size > 1000+32768 {
+			t.Errorf("Size() = %v after exceeding the limit", size)
+		}
+	}
+
+	v := BytesEncodings{Std: data[:100000], Hex: data[:100000]}
+	want, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	w := jwriter.Writer{}
+	w.SetMaxSize(len(want))
+	v.MarshalEasyJSON(&w)
+	if got, err := w.BuildBytes(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("BuildBytes() error %v within the limit", err)
+	}
+}
+
+func TestStreamWriterMaxSize(t *testing.T) {
+	var out bytes.Buffer
+	w := jwriter.NewWriter(&out, 1000)
+	w.SetMaxSize(100000)
+	largeSlice.MarshalEasyJSON(w)
+	if err := w.Flush(); !isMaxSizeError(err, 100000) {
+		t.Errorf("Flush() error %v, want *MaxSizeError", err)
+	}
+	if out.Len() > 100000+32768 {
+		t.Errorf("%v bytes written after exceeding the limit", out.Len())
+	}
+}
+
+func TestMarshalMaxSize(t *testing.T) {
+	opts := easyjson.MarshalOptions{MaxSize: 1000}
+
+	if _, err := opts.Marshal(largeSlice); !isMaxSizeError(err, 1000) {
+		t.Errorf("Marshal() error %v, want *MaxSizeError", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := opts.MarshalToWriter(largeSlice, &out); !isMaxSizeError(err, 1000) || out.Len() != 0 {
+		t.Errorf("MarshalToWriter() error %v with %v bytes written, want *MaxSizeError", err, out.Len())
+	}
+
+	rec := httptest.NewRecorder()
+	started, _, err := opts.MarshalToHTTPResponseWriter(largeSlice, rec)
+	if started || !isMaxSizeError(err, 1000) || rec.Body.Len() != 0 {
+		t.Errorf("MarshalToHTTPResponseWriter() = %v, %v with %v bytes written, want *MaxSizeError", started, err, rec.Body.Len())
+	}
+
+	if got, err := opts.Marshal(EncodingFlagsTestSlice{F: []string{"a"}}); err != nil || string(got) != `{"F":["a"]}` {
+		t.Errorf("Marshal() = %s, %v within the limit", got, err)
+	}
+	if _, err := easyjson.Marshal(largeSlice); err != nil {
+		t.Errorf("Marshal() error %v without a limit", err)
+	}
+}
+
+func TestWriterMaxSizeSortedDumpTo(t *testing.T) {
+	w := jwriter.Writer{Flags: jwriter.SortMapKeys}
+	w.SetMaxSize(1000)
+	MapStringString{"a": strings.Repeat("x", 100000)}.MarshalEasyJSON(&w)
+	var out bytes.Buffer
+	if _, err := w.DumpTo(&out); !isMaxSizeError(err, 1000) || out.Len() != 0 {
+		t.Errorf("DumpTo() error %v with %v bytes written, want *MaxSizeError", err, out.Len())
+	}
+}
+
+func TestStreamWriterMaxSizeSorted(t *testing.T) {
+	var out bytes.Buffer
+	w := jwriter.NewWriter(&out, 100)
+	w.Flags = jwriter.SortMapKeys
+	w.SetMaxSize(50000)
+	w.RawByte('[')
+	w.String(strings.Repeat("x", 40000))
+	w.RawByte(',')
+	m := MapStringString{}
+	for i := 0; i < 1000; i++ {
+		m[strconv.Itoa(i)] = "0123456789"
+	}
+	m.MarshalEasyJSON(w)
+	if err := w.Err(); !isMaxSizeError(err, 50000) {
+		t.Errorf("got error %v after the map, want *MaxSizeError", err)
+	}
+}
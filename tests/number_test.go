@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestInterfaceNumbers(t *testing.T) {
+	data := []byte(`{"Value":9007199254740993,"Slice":[1,2.5],"Map":{"a":{"b":[-3]}}}`)
+
+	for i, test := range []struct {
+		Lexer jlexer.Lexer
+		Want  NestedInterfaces
+	}{
+		{
+			Lexer: jlexer.Lexer{UseNumber: true},
+			Want: NestedInterfaces{
+				Value: json.Number("9007199254740993"),
+				Slice: []interface{}{json.Number("1"), json.Number("2.5")},
+				Map:   map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{json.Number("-3")}}},
+			},
+		},
+		{
+			Lexer: jlexer.Lexer{UseInt64: true},
+			Want: NestedInterfaces{
+				Value: int64(9007199254740993),
+				Slice: []interface{}{int64(1), 2.5},
+				Map:   map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{int64(-3)}}},
+			},
+		},
+	} {
+		l := test.Lexer
+		l.Data = data
+
+		var got NestedInterfaces
+		got.UnmarshalEasyJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d] UnmarshalEasyJSON() error: %v", i, err)
+		} else if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] UnmarshalEasyJSON() = %#v; want %#v", i, got, test.Want)
+		}
+	}
+}
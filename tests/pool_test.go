@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestUnmarshalAllocs(t *testing.T) {
+	data := []byte(`{"string": "test", "slice": [1, 2], "int": 3}`)
+	v := ErrorStruct{Slice: make([]int, 0, 4)}
+
+	if err := easyjson.Unmarshal(data, &v); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		v.Slice = v.Slice[:0]
+		_ = easyjson.Unmarshal(data, &v)
+	})
+	// The only allocation left is the decoded string value.
+	if allocs > 1 {
+		t.Errorf("easyjson.Unmarshal() allocs = %v; want at most 1", allocs)
+	}
+}
+
+// strictUnmarshaler sets lexer options that must not apply to later unmarshaling.
+type strictUnmarshaler struct{}
+
+func (strictUnmarshaler) UnmarshalEasyJSON(in *jlexer.Lexer) {
+	in.DisallowDuplicateKeys = true
+	in.MaxDepth = 1
+	in.SkipRecursive()
+}
+
+func TestUnmarshalPooledLexerOptions(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		_ = easyjson.Unmarshal([]byte(`{}`), strictUnmarshaler{})
+
+		var v MapStringString
+		if err := easyjson.Unmarshal([]byte(`{"a": "1", "a": "2"}`), &v); err != nil {
+			t.Fatalf("easyjson.Unmarshal() error: %v", err)
+		}
+		var s []interface{}
+		if err := easyjson.Unmarshal([]byte(`[[[1]]]`), (*sliceOfInterfaces)(&s)); err != nil {
+			t.Fatalf("easyjson.Unmarshal() error: %v", err)
+		}
+	}
+}
+
+// sliceOfInterfaces decodes arbitrary nested arrays.
+type sliceOfInterfaces []interface{}
+
+func (s *sliceOfInterfaces) UnmarshalEasyJSON(in *jlexer.Lexer) {
+	v, _ := in.Interface().([]interface{})
+	*s = v
+}
+
+func TestMarshalPooledWriter(t *testing.T) {
+	if _, err := easyjson.Marshal(BigNumbers{Rat: big.NewRat(1, 3)}); err == nil {
+		t.Fatalf("easyjson.Marshal() expected error for 1/3")
+	}
+	for i := 0; i < 10; i++ {
+		got, err := easyjson.Marshal(Struct{Test: "a"})
+		if err != nil {
+			t.Fatalf("easyjson.Marshal() error: %v", err)
+		}
+		if want := `{"Test":"a"}`; string(got) != want {
+			t.Errorf("easyjson.Marshal() = %s; want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func TestValidateRaw(t *testing.T) {
+	for _, test := range []struct {
+		w    jwriter.Writer
+		raw  string
+		want string
+		err  bool
+	}{
+		{raw: ` { "a" : [ 1, 2 ] } `, want: `{"Field":{"a":[1,2]},"Field2":"x"}`},
+		{raw: `"<&>"`, want: `{"Field":"\u003c\u0026\u003e","Field2":"x"}`},
+		{w: jwriter.Writer{NoEscapeHTML: true}, raw: `"<&>"`, want: `{"Field":"<&>","Field2":"x"}`},
+		{w: jwriter.Writer{Indent: " "}, raw: `{"a" : 1}`, want: "{\n \"Field\": {\n  \"a\": 1\n },\n \"Field2\": \"x\"\n}"},
+		{raw: `{"a":}`, err: true},
+		{raw: `1 2`, err: true},
+		{raw: `{"a":1`, err: true},
+	} {
+		w := test.w
+		w.ValidateRaw = true
+		v := Raw{Field: []byte(test.raw), Field2: "x"}
+		v.MarshalEasyJSON(&w)
+		got, err := w.BuildBytes()
+
+		if test.err {
+			if _, ok := err.(*json.SyntaxError); !ok {
+				t.Errorf("%s: got error %v, want a syntax error", test.raw, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: BuildBytes() error: %v", test.raw, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.raw, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package tests
+
+import "strings"
+
+type SortedKey string
+
+func (k SortedKey) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(k))), nil
+}
+
+func (k *SortedKey) UnmarshalText(text []byte) error {
+	*k = SortedKey(strings.ToLower(string(text)))
+	return nil
+}
+
+//easyjson:json
+type SortedMaps struct {
+	Strings map[string]int
+	Ints    map[int]string
+	Text    map[SortedKey]int
+	Nested  map[string]map[string]int
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/buffer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+var sortedMapsValue = SortedMaps{
+	Strings: map[string]int{"c": 3, "a": 1, "b": 2, "ab": 4, "é": 5, "<": 6},
+	Ints:    map[int]string{10: "ten", -1: "minus one", 2: "two", 100: "hundred"},
+	Text:    map[SortedKey]int{"b": 2, "c": 3, "a": 1},
+	Nested:  map[string]map[string]int{"y": {"b": 2, "a": 1}, "x": {}},
+}
+
+var sortedMapsString = `{` +
+	`"Strings":{"\u003c":6,"a":1,"ab":4,"b":2,"c":3,"é":5},` +
+	`"Ints":{"-1":"minus one","10":"ten","100":"hundred","2":"two"},` +
+	`"Text":{"A":1,"B":2,"C":3},` +
+	`"Nested":{"x":{},"y":{"a":1,"b":2}}` +
+	`}`
+
+func TestSortMapKeysOption(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		data, err := easyjson.Marshal(sortedMapsValue)
+		if err != nil {
+			t.Fatalf("easyjson.Marshal() error: %v", err)
+		}
+		if string(data) != sortedMapsString {
+			t.Fatalf("easyjson.Marshal() = %s, want %s", data, sortedMapsString)
+		}
+	}
+}
+
+func TestSortMapKeysFlag(t *testing.T) {
+	for _, test := range []struct {
+		flags jwriter.Flags
+		v     easyjson.Marshaler
+		want  string
+	}{
+		{jwriter.SortMapKeys, MapStringString{"c": "3", "a": "1", "b": "2"}, `{"a":"1","b":"2","c":"3"}`},
+		{jwriter.SortMapKeys, MapIntString{3: "c", 1: "a", 20: "b"}, `{"1":"a","20":"b","3":"c"}`},
+		{jwriter.SortMapKeys, KeyWithEncodingMarshalers{5: "hello"}, `{"hello":"hello"}`},
+		{jwriter.SortMapKeys, MapStringString{}, `{}`},
+		{jwriter.SortMapKeys | jwriter.NilMapAsEmpty, MapStringString(nil), `{}`},
+	} {
+		for i := 0; i < 10; i++ {
+			w := jwriter.Writer{Flags: test.flags}
+			test.v.MarshalEasyJSON(&w)
+			got, err := w.BuildBytes()
+			if err != nil {
+				t.Fatalf("%T: BuildBytes() error: %v", test.v, err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("%T: got %s, want %s", test.v, got, test.want)
+			}
+		}
+	}
+}
+
+func TestSortMapKeysIndent(t *testing.T) {
+	want := `{
+  "Strings": {
+    "\u003c": 6,
+    "a": 1,
+    "ab": 4,
+    "b": 2,
+    "c": 3,
+    "é": 5
+  },
+  "Ints": {
+    "-1": "minus one",
+    "10": "ten",
+    "100": "hundred",
+    "2": "two"
+  },
+  "Text": {
+    "A": 1,
+    "B": 2,
+    "C": 3
+  },
+  "Nested": {
+    "x": {},
+    "y": {
+      "a": 1,
+      "b": 2
+    }
+  }
+}`
+	for i := 0; i < 10; i++ {
+		got, err := easyjson.MarshalIndent(sortedMapsValue, "", "  ")
+		if err != nil {
+			t.Fatalf("easyjson.MarshalIndent() error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("easyjson.MarshalIndent() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestSortMapKeysPool(t *testing.T) {
+	w := jwriter.Writer{Buffer: buffer.Buffer{Pool: buffer.NewPool(buffer.PoolConfig{StartSize: 8, PooledSize: 8, MaxSize: 16})}}
+	sortedMapsValue.MarshalEasyJSON(&w)
+	got, err := w.BuildBytes()
+	if err != nil {
+		t.Fatalf("BuildBytes() error: %v", err)
+	}
+	if string(got) != sortedMapsString {
+		t.Errorf("got %s, want %s", got, sortedMapsString)
+	}
+}
+
+func TestSortMapKeysEscaped(t *testing.T) {
+	v := MapStringString{"é": "4", "b": "3", "A": "2", "<": "1"}
+	std, err := json.Marshal(map[string]string(v))
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	for _, test := range []struct {
+		w    jwriter.Writer
+		want string
+	}{
+		{jwriter.Writer{}, string(std)},
+		{jwriter.Writer{NoEscapeHTML: true}, `{"<":"1","A":"2","b":"3","é":"4"}`},
+		{jwriter.Writer{EscapeNonASCII: true}, `{"\u003c":"1","A":"2","b":"3","\u00e9":"4"}`},
+	} {
+		w := test.w
+		w.Flags = jwriter.SortMapKeys
+		v.MarshalEasyJSON(&w)
+		got, err := w.BuildBytes()
+		if err != nil {
+			t.Fatalf("BuildBytes() error: %v", err)
+		}
+		if string(got) != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	data := "{\"Test\":\"a\"}\n{\"Test\":\"b\"} {\"Test\":\"c\"}\n\nnull\n{\"Test\":\n\"d\"}\n"
+	want := []Struct{{Test: "a"}, {Test: "b"}, {Test: "c"}, {}, {Test: "d"}}
+
+	d := easyjson.NewStreamDecoder(iotest.OneByteReader(strings.NewReader(data)))
+	var got []Struct
+	for {
+		var v Struct
+		err := d.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error: %v", err)
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v; want %+v", got, want)
+	}
+}
+
+func TestStreamDecoderErrors(t *testing.T) {
+	for i, test := range []struct {
+		data string
+		want error
+	}{
+		{data: `[1] [2`, want: io.ErrUnexpectedEOF},
+		{data: `[1] [2, x]`},
+	} {
+		d := easyjson.NewStreamDecoder(strings.NewReader(test.data))
+
+		var v ErrorIntSlice
+		if err := d.Decode(&v); err != nil {
+			t.Errorf("[%d] first Decode() error: %v", i, err)
+		}
+		err := d.Decode(&v)
+		if err == nil || (test.want != nil && err != test.want) {
+			t.Errorf("[%d] second Decode() error = %v; want %v", i, err, test.want)
+		}
+		if err2 := d.Decode(&v); err2 != err {
+			t.Errorf("[%d] third Decode() error = %v; want %v", i, err2, err)
+		}
+	}
+}
+
+func TestStreamEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := easyjson.NewStreamEncoder(&buf)
+
+	for _, v := range []easyjson.Marshaler{Struct{Test: "a"}, (*Struct)(nil), ErrorIntSlice{1, 2}} {
+		if err := e.Encode(v); err != nil {
+			t.Fatalf("Encode() error: %v", err)
+		}
+	}
+
+	want := "{\"Test\":\"a\"}\nnull\n[1,2]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Encode() = %q; want %q", got, want)
+	}
+}
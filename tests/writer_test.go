@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+type sizeWriter struct {
+	bytes.Buffer
+	w       *jwriter.Writer
+	maxSize int
+}
+
+func (s *sizeWriter) Write(data []byte) (int, error) {
+	if size := s.w.Size(); size > s.maxSize {
+		s.maxSize = size
+	}
+	return s.Buffer.Write(data)
+}
+
+func TestStreamWriter(t *testing.T) {
+	v := make(Ints, 100000)
+	for i := range v {
+		v[i] = i
+	}
+	want, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+
+	out := &sizeWriter{}
+	w := jwriter.NewWriter(out, 4096)
+	out.w = w
+	v.MarshalEasyJSON(w)
+	if out.Len() == 0 {
+		t.Errorf("no data written before Flush()")
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("NewWriter() output differs from easyjson.Marshal()")
+	}
+	if out.maxSize > 1<<16 {
+		t.Errorf("buffered %v bytes; want at most %v", out.maxSize, 1<<16)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(data []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestStreamWriterError(t *testing.T) {
+	v := make(Ints, 100000)
+
+	w := jwriter.NewWriter(failingWriter{}, 4096)
+	v.MarshalEasyJSON(w)
+	if w.Error == nil {
+		t.Errorf("Error is not set after the output failed")
+	}
+	if err := w.Flush(); err == nil {
+		t.Errorf("Flush() expected error")
+	}
+}
+
+func TestWriterFlushNoOutput(t *testing.T) {
+	w := jwriter.Writer{}
+	Ints{1, 2}.MarshalEasyJSON(&w)
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if got, err := w.BuildBytes(); err != nil || string(got) != "[1,2]" {
+		t.Errorf("BuildBytes() = %s, %v after Flush(); want [1,2]", got, err)
+	}
+}
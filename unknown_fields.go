@@ -25,8 +25,9 @@ func (s UnknownFieldsProxy) MarshalUnknowns(out *jwriter.Writer, first bool) {
 		} else {
 			out.RawByte(',')
 		}
+		out.RawIndent()
 		out.String(string(key))
-		out.RawByte(':')
+		out.RawColon()
 		out.Raw(val, nil)
 	}
 }